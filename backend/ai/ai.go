@@ -0,0 +1,139 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+type Level int64
+
+const (
+	// LevelRandom plays any legal move
+	LevelRandom Level = iota
+	// LevelHeuristic wins or blocks when it can, otherwise plays the move with the best evaluation
+	LevelHeuristic
+	// LevelDepthLimited searches a couple of moves ahead
+	LevelDepthLimited
+	// LevelPerfect searches to the end of the game when the board is small enough, as deep as it can otherwise
+	LevelPerfect
+)
+
+var LevelName = map[Level]string{
+	LevelRandom:       "random",
+	LevelHeuristic:    "heuristic",
+	LevelDepthLimited: "depth-limited",
+	LevelPerfect:      "perfect",
+}
+
+// Bots are regular players named BotPrefix + level name, they can't log in since they have no password
+const BotPrefix = "bot-"
+
+var ErrNoLegalMove = errors.New("no legal move")
+
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range LevelName {
+		if levelName == name {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown level: %s", name)
+}
+
+// BotName returns the name of the player playing at level
+func BotName(level Level) string {
+	return BotPrefix + LevelName[level]
+}
+
+// BotLevel returns the level of the bot named name, false if it isn't a bot
+func BotLevel(name string) (Level, bool) {
+	if !strings.HasPrefix(name, BotPrefix) {
+		return 0, false
+	}
+	level, err := ParseLevel(strings.TrimPrefix(name, BotPrefix))
+	return level, err == nil
+}
+
+// ChooseMove returns the move of the player whose turn it is
+func ChooseMove(game types.Game, level Level) (types.Placement, error) {
+	variant, err := gamerules.GetSizedVariant(game.Variant, game.BoardSize)
+	if err != nil {
+		return types.Placement{}, err
+	}
+	moves := variant.LegalMoves(game)
+	if len(moves) == 0 {
+		return types.Placement{}, ErrNoLegalMove
+	}
+
+	switch level {
+	case LevelRandom:
+		return moves[rand.Intn(len(moves))], nil
+	case LevelHeuristic:
+		return chooseHeuristic(variant, game, moves), nil
+	case LevelDepthLimited:
+		return search(variant, game, depthLimitedDepth), nil
+	case LevelPerfect:
+		depth := maxSearchDepth
+		if cells := emptyCells(game.Board); cells <= fullSearchCells {
+			depth = cells
+		}
+		return search(variant, game, depth), nil
+	default:
+		return types.Placement{}, fmt.Errorf("unknown level: %d", level)
+	}
+}
+
+// chooseHeuristic wins right away if possible, otherwise blocks a win of the opponent on their next move,
+// otherwise plays the move leaving the best evaluated position
+func chooseHeuristic(variant gamerules.Variant, game types.Game, moves []types.Placement) types.Placement {
+	mark := game.Turn
+
+	var best []types.Placement
+	bestScore := 0
+	for _, move := range moves {
+		next := variant.ApplyMove(game, move)
+		if winner(variant.Outcome(next)) == mark {
+			return move
+		}
+		score := evaluate(next, mark)
+		if len(best) == 0 || score > bestScore {
+			best, bestScore = []types.Placement{move}, score
+		} else if score == bestScore {
+			best = append(best, move)
+		}
+	}
+
+	// The opponent wins by playing where we would have won on their turn
+	opponent := game
+	opponent.Turn = otherMark(mark)
+	for _, move := range moves {
+		next := variant.ApplyMove(opponent, move)
+		if winner(variant.Outcome(next)) == opponent.Turn {
+			return move
+		}
+	}
+
+	return best[rand.Intn(len(best))]
+}
+
+// winner returns the mark of the winner of the outcome, empty if none
+func winner(outcome types.Outcome) string {
+	switch outcome.Result {
+	case types.ResultXWins:
+		return "X"
+	case types.ResultOWins:
+		return "O"
+	}
+	return ""
+}
+
+func otherMark(mark string) string {
+	if mark == "X" {
+		return "O"
+	}
+	return "X"
+}
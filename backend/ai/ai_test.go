@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// gameAfter returns the game of the variant after the moves, X playing first
+func gameAfter(t *testing.T, variant string, cells [][2]int) types.Game {
+	t.Helper()
+	moves := make([]types.PlayedMove, len(cells))
+	for i, cell := range cells {
+		moves[i] = types.PlayedMove{Ply: int64(i + 1), Placement: types.Placement{X: cell[0], Y: cell[1]}}
+	}
+	states, err := gamerules.Replay(variant, types.BoardSize{}, moves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return states[len(states)-1]
+}
+
+func TestChooseMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant string
+		cells   [][2]int
+		// Any of them
+		want [][2]int
+	}{
+		{
+			name:    "takes the win before blocking",
+			variant: "classic",
+			cells:   [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+			want:    [][2]int{{0, 2}},
+		},
+		{
+			name:    "blocks the win of the opponent",
+			variant: "classic",
+			cells:   [][2]int{{0, 0}, {1, 1}, {2, 2}, {1, 0}},
+			want:    [][2]int{{1, 2}},
+		},
+		{
+			name:    "completes five",
+			variant: "gomoku",
+			cells:   [][2]int{{7, 3}, {0, 0}, {7, 4}, {0, 2}, {7, 5}, {0, 4}, {7, 6}, {0, 6}},
+			want:    [][2]int{{7, 2}, {7, 7}},
+		},
+		{
+			name:    "blocks a four",
+			variant: "gomoku",
+			cells:   [][2]int{{5, 0}, {5, 1}, {10, 10}, {5, 2}, {12, 12}, {5, 3}, {0, 14}, {5, 4}},
+			want:    [][2]int{{5, 5}},
+		},
+	}
+	for _, test := range tests {
+		game := gameAfter(t, test.variant, test.cells)
+		for _, level := range []Level{LevelHeuristic, LevelDepthLimited, LevelPerfect} {
+			move, err := ChooseMove(game, level)
+			if err != nil {
+				t.Fatalf("%s at level %s: %v", test.name, LevelName[level], err)
+			}
+			found := false
+			for _, cell := range test.want {
+				found = found || (move.X == cell[0] && move.Y == cell[1])
+			}
+			if !found {
+				t.Errorf("%s at level %s: played %d,%d, want one of %v", test.name, LevelName[level], move.X, move.Y, test.want)
+			}
+		}
+	}
+}
+
+func TestChooseMoveRandom(t *testing.T) {
+	// X O X
+	// X O O
+	// O X .
+	game := gameAfter(t, "classic", [][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 1}, {1, 0}, {1, 2}, {2, 1}, {2, 0}})
+	for i := 0; i < 20; i++ {
+		move, err := ChooseMove(game, LevelRandom)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if move.X != 2 || move.Y != 2 {
+			t.Fatalf("played %d,%d, want the last empty cell 2,2", move.X, move.Y)
+		}
+	}
+
+	game = gameAfter(t, "classic", [][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 1}, {1, 0}, {1, 2}, {2, 1}, {2, 0}, {2, 2}})
+	if _, err := ChooseMove(game, LevelRandom); !errors.Is(err, ErrNoLegalMove) {
+		t.Errorf("move on a full board: %v, want ErrNoLegalMove", err)
+	}
+}
@@ -0,0 +1,102 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+var ErrNotSolved = errors.New("analysis is only available for classic games")
+
+// Analyze evaluates every position of a classic game, from the empty board to the position after the last move
+func Analyze(game types.Game, moves []types.PlayedMove) ([]types.PositionAnalysis, error) {
+	if game.BoardSize != gamerules.Classic || game.MetaBoard != nil {
+		return nil, ErrNotSolved
+	}
+
+	var p position
+	var analyses []types.PositionAnalysis
+	for ply := 0; ; ply++ {
+		analysis := analyzePosition(p, int64(ply))
+		if ply == len(moves) {
+			analyses = append(analyses, analysis)
+			return analyses, nil
+		}
+
+		move := moves[ply]
+		if !gamerules.InBounds(toBoard(p), move.X, move.Y) || p[move.X*3+move.Y] != 0 || p.winner() != 0 {
+			return nil, fmt.Errorf("invalid move %d,%d at ply %d", move.X, move.Y, move.Ply)
+		}
+		next := p
+		next[move.X*3+move.Y] = p.turn()
+		played := types.MoveEvaluation{X: move.X, Y: move.Y, Evaluation: reverse(evaluatePosition(next))}
+		analysis.PlayedMove = &played
+		analysis.Blunder = resultRank(played.Evaluation) < resultRank(analysis.Evaluation)
+
+		analyses = append(analyses, analysis)
+		p = next
+	}
+}
+
+// analyzePosition returns the evaluation of the position and its best moves, all of them when several are as good
+func analyzePosition(p position, ply int64) types.PositionAnalysis {
+	analysis := types.PositionAnalysis{
+		Ply:        ply,
+		Board:      toBoard(p),
+		Turn:       markName(p.turn()),
+		Evaluation: evaluatePosition(p),
+	}
+	if p.winner() != 0 || p.full() {
+		return analysis
+	}
+
+	var moves []types.MoveEvaluation
+	for cell := range p {
+		if p[cell] != 0 {
+			continue
+		}
+		next := p
+		next[cell] = p.turn()
+		moves = append(moves, types.MoveEvaluation{X: cell / 3, Y: cell % 3, Evaluation: reverse(evaluatePosition(next))})
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return better(moves[i].Evaluation, moves[j].Evaluation)
+	})
+	for _, move := range moves {
+		if rank(move.Evaluation) == rank(analysis.Evaluation) {
+			analysis.BestMoves = append(analysis.BestMoves, move)
+		}
+	}
+	return analysis
+}
+
+// resultRank orders the results only, a slower win isn't a blunder
+func resultRank(evaluation types.Evaluation) int {
+	switch evaluation.Result {
+	case types.EvaluationWin:
+		return 1
+	case types.EvaluationLoss:
+		return -1
+	}
+	return 0
+}
+
+func toBoard(p position) [][]string {
+	board := gamerules.NewBoard(gamerules.Classic)
+	for cell, mark := range p {
+		if mark != 0 {
+			board[cell/3][cell%3] = markName(mark)
+		}
+	}
+	return board
+}
+
+func markName(mark uint8) string {
+	if mark == 1 {
+		return "X"
+	}
+	return "O"
+}
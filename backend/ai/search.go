@@ -0,0 +1,188 @@
+package ai
+
+import (
+	"math/rand"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+const (
+	depthLimitedDepth = 2
+	// Boards with at most fullSearchCells empty cells are searched to the end, which is perfect play on 3x3
+	fullSearchCells = 9
+	maxSearchDepth  = 3
+	// Above any evaluation, a win sooner scores higher than a win later
+	winScore = 1 << 30
+)
+
+// search returns the best move found by a negamax search with alpha-beta pruning, depth moves ahead.
+// Equally good moves are picked at random so bots don't always play the same game
+func search(variant gamerules.Variant, game types.Game, depth int) types.Placement {
+	var best []types.Placement
+	bestScore := -winScore - 1
+	for _, move := range candidateMoves(variant, game) {
+		next := variant.ApplyMove(game, move)
+		// The window is only narrowed strictly above the best score so every equal move is found
+		score := -negamax(variant, next, depth-1, 1, -winScore-1, -bestScore+1)
+		if len(best) == 0 || score > bestScore {
+			best, bestScore = []types.Placement{move}, score
+		} else if score == bestScore {
+			best = append(best, move)
+		}
+	}
+	return best[rand.Intn(len(best))]
+}
+
+// negamax returns the score of the game for the player whose turn it is, ply moves after the searched position
+func negamax(variant gamerules.Variant, game types.Game, depth int, ply int, alpha int, beta int) int {
+	outcome := variant.Outcome(game)
+	switch winner(outcome) {
+	case game.Turn:
+		return winScore - ply
+	case otherMark(game.Turn):
+		return -(winScore - ply)
+	}
+	if outcome.Result == types.ResultDraw {
+		return 0
+	}
+	if depth <= 0 {
+		return evaluate(game, game.Turn)
+	}
+
+	best := -winScore - 1
+	for _, move := range candidateMoves(variant, game) {
+		next := variant.ApplyMove(game, move)
+		score := -negamax(variant, next, depth-1, ply+1, -beta, -alpha)
+		if score > best {
+			best = score
+		}
+		if best > alpha {
+			alpha = best
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// candidateMoves are the legal moves worth searching: on boards bigger than classic
+// only the cells next to a mark, moves far from every mark being almost never better
+func candidateMoves(variant gamerules.Variant, game types.Game) []types.Placement {
+	moves := variant.LegalMoves(game)
+	if len(moves) <= fullSearchCells {
+		return moves
+	}
+
+	var candidates []types.Placement
+	for _, move := range moves {
+		if hasNeighbour(game.Board, move.X, move.Y) {
+			candidates = append(candidates, move)
+		}
+	}
+	if len(candidates) == 0 {
+		// Empty board, start in the middle of the legal moves
+		return []types.Placement{moves[len(moves)/2]}
+	}
+	return candidates
+}
+
+func emptyCells(board [][]string) int {
+	count := 0
+	for _, row := range board {
+		for _, cell := range row {
+			if cell == "" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func hasNeighbour(board [][]string, x int, y int) bool {
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if gamerules.InBounds(board, x+dx, y+dy) && board[x+dx][y+dy] != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evaluate scores the game for mark from the lines still open: every window of WinLength cells
+// holding only marks of one player counts for that player, exponentially in the number of marks.
+// Ultimate games are evaluated on each open sub-board and, weighted higher, on the meta-board
+func evaluate(game types.Game, mark string) int {
+	if game.MetaBoard == nil {
+		return evaluateBoard(game.Board, game.WinLength, mark)
+	}
+
+	score := 100 * evaluateBoard(game.MetaBoard, 3, mark)
+	for subX := range game.MetaBoard {
+		for subY := range game.MetaBoard[subX] {
+			if game.MetaBoard[subX][subY] != "" {
+				continue
+			}
+			sub := make([][]string, 3)
+			for x := range sub {
+				sub[x] = game.Board[subX*3+x][subY*3 : subY*3+3]
+			}
+			score += evaluateBoard(sub, 3, mark)
+		}
+	}
+	return score
+}
+
+var directions = [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+
+func evaluateBoard(board [][]string, winLength int, mark string) int {
+	score := 0
+	for x := range board {
+		for y := range board[x] {
+			for _, direction := range directions {
+				score += evaluateWindow(board, x, y, direction, winLength, mark)
+			}
+		}
+	}
+	return score
+}
+
+func evaluateWindow(board [][]string, x int, y int, direction [2]int, winLength int, mark string) int {
+	own, opponent := 0, 0
+	for i := 0; i < winLength; i++ {
+		cellX, cellY := x+i*direction[0], y+i*direction[1]
+		if !gamerules.InBounds(board, cellX, cellY) {
+			return 0
+		}
+		switch board[cellX][cellY] {
+		case "":
+		case mark:
+			own++
+		case otherMark(mark):
+			opponent++
+		default:
+			// Drawn Ultimate sub-board, nobody can use the window
+			return 0
+		}
+	}
+	if own > 0 && opponent > 0 {
+		return 0
+	}
+	if own > 0 {
+		return pow10(own)
+	}
+	if opponent > 0 {
+		return -pow10(opponent)
+	}
+	return 0
+}
+
+func pow10(n int) int {
+	result := 1
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"sync"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// Classic tic-tac-toe is small enough to be solved: every reachable position (5,478, 765 up to symmetry)
+// is evaluated once, on first use, and kept in a transposition table keyed by its canonical encoding
+
+// position is a classic board, cell x*3+y being 0 when empty, 1 for X and 2 for O
+type position [9]uint8
+
+var (
+	solveOnce sync.Once
+	solved    map[uint32]types.Evaluation
+)
+
+// symmetries maps each cell to its image by the 8 rotations and reflections of the board
+var symmetries = func() [8][9]int {
+	var result [8][9]int
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 3; y++ {
+			images := [8][2]int{
+				{x, y}, {y, 2 - x}, {2 - x, 2 - y}, {2 - y, x},
+				{x, 2 - y}, {2 - x, y}, {y, x}, {2 - y, 2 - x},
+			}
+			for i, image := range images {
+				result[i][x*3+y] = image[0]*3 + image[1]
+			}
+		}
+	}
+	return result
+}()
+
+var classicLines = [8][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// key is the base 3 encoding of the position, the smallest one among its symmetries
+func (p position) key() uint32 {
+	var best uint32
+	for i, symmetry := range symmetries {
+		var key uint32
+		for cell := range p {
+			key = key*3 + uint32(p[symmetry[cell]])
+		}
+		if i == 0 || key < best {
+			best = key
+		}
+	}
+	return best
+}
+
+// turn returns the mark to play, X starting
+func (p position) turn() uint8 {
+	count := 0
+	for _, cell := range p {
+		if cell != 0 {
+			count++
+		}
+	}
+	if count%2 == 0 {
+		return 1
+	}
+	return 2
+}
+
+// winner returns the mark with three in a row, 0 if none
+func (p position) winner() uint8 {
+	for _, line := range classicLines {
+		if p[line[0]] != 0 && p[line[0]] == p[line[1]] && p[line[1]] == p[line[2]] {
+			return p[line[0]]
+		}
+	}
+	return 0
+}
+
+func (p position) full() bool {
+	for _, cell := range p {
+		if cell == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluatePosition returns the evaluation of a reachable position from the table
+func evaluatePosition(p position) types.Evaluation {
+	solveOnce.Do(func() {
+		solved = make(map[uint32]types.Evaluation)
+		solve(position{})
+	})
+	return solved[p.key()]
+}
+
+func solve(p position) types.Evaluation {
+	key := p.key()
+	if evaluation, ok := solved[key]; ok {
+		return evaluation
+	}
+
+	var evaluation types.Evaluation
+	switch {
+	case p.winner() != 0:
+		// The previous player just won
+		evaluation = types.Evaluation{Result: types.EvaluationLoss}
+	case p.full():
+		evaluation = types.Evaluation{Result: types.EvaluationDraw}
+	default:
+		turn := p.turn()
+		for cell := range p {
+			if p[cell] != 0 {
+				continue
+			}
+			next := p
+			next[cell] = turn
+			moveEvaluation := reverse(solve(next))
+			if cell == firstEmpty(p) || better(moveEvaluation, evaluation) {
+				evaluation = moveEvaluation
+			}
+		}
+	}
+	solved[key] = evaluation
+	return evaluation
+}
+
+func firstEmpty(p position) int {
+	for cell := range p {
+		if p[cell] == 0 {
+			return cell
+		}
+	}
+	return -1
+}
+
+// reverse turns the evaluation of a position for the opponent into the evaluation of the move leading to it
+func reverse(evaluation types.Evaluation) types.Evaluation {
+	switch evaluation.Result {
+	case types.EvaluationWin:
+		evaluation.Result = types.EvaluationLoss
+	case types.EvaluationLoss:
+		evaluation.Result = types.EvaluationWin
+	}
+	evaluation.Distance++
+	return evaluation
+}
+
+// rank orders evaluations: faster wins first, then draws, then slower losses
+func rank(evaluation types.Evaluation) int {
+	switch evaluation.Result {
+	case types.EvaluationWin:
+		return 100 - evaluation.Distance
+	case types.EvaluationLoss:
+		return -100 + evaluation.Distance
+	}
+	return 0
+}
+
+func better(a types.Evaluation, b types.Evaluation) bool {
+	return rank(a) > rank(b)
+}
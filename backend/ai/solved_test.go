@@ -0,0 +1,127 @@
+package ai
+
+import (
+	"testing"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// reachable adds to positions p and every position reachable from it, a game stopping at the first win
+func reachable(p position, positions map[position]bool) {
+	if positions[p] {
+		return
+	}
+	positions[p] = true
+	if p.winner() != 0 || p.full() {
+		return
+	}
+	for cell := range p {
+		if p[cell] == 0 {
+			next := p
+			next[cell] = p.turn()
+			reachable(next, positions)
+		}
+	}
+}
+
+// minimax evaluates p like solve but position by position, without the symmetries
+func minimax(p position, evaluations map[position]types.Evaluation) types.Evaluation {
+	if evaluation, ok := evaluations[p]; ok {
+		return evaluation
+	}
+	var evaluation types.Evaluation
+	switch {
+	case p.winner() != 0:
+		evaluation = types.Evaluation{Result: types.EvaluationLoss}
+	case p.full():
+		evaluation = types.Evaluation{Result: types.EvaluationDraw}
+	default:
+		first := true
+		for cell := range p {
+			if p[cell] != 0 {
+				continue
+			}
+			next := p
+			next[cell] = p.turn()
+			if moveEvaluation := reverse(minimax(next, evaluations)); first || better(moveEvaluation, evaluation) {
+				evaluation, first = moveEvaluation, false
+			}
+		}
+	}
+	evaluations[p] = evaluation
+	return evaluation
+}
+
+func TestSolvedPositions(t *testing.T) {
+	positions := make(map[position]bool)
+	reachable(position{}, positions)
+	if len(positions) != 5478 {
+		t.Fatalf("%d reachable positions, want 5478", len(positions))
+	}
+
+	evaluatePosition(position{})
+	if len(solved) != 765 {
+		t.Fatalf("%d solved positions, want 765 up to symmetry", len(solved))
+	}
+
+	evaluations := make(map[position]types.Evaluation)
+	for p := range positions {
+		want := minimax(p, evaluations)
+		if got := evaluatePosition(p); got != want {
+			t.Fatalf("evaluation of %v = %+v, want %+v", p, got, want)
+		}
+		for _, symmetry := range symmetries {
+			var image position
+			for cell := range p {
+				image[symmetry[cell]] = p[cell]
+			}
+			if image.key() != p.key() {
+				t.Fatalf("%v and its image %v have different keys", p, image)
+			}
+			if got := minimax(image, evaluations); got != want {
+				t.Fatalf("evaluation of %v = %+v, want %+v like %v", image, got, want, p)
+			}
+		}
+	}
+}
+
+func TestPerfectPlayDraws(t *testing.T) {
+	if evaluation := evaluatePosition(position{}); evaluation != (types.Evaluation{Result: types.EvaluationDraw, Distance: 9}) {
+		t.Fatalf("evaluation of the empty board = %+v, want a draw in 9", evaluation)
+	}
+
+	// Equal moves are picked at random, so several games
+	for i := 0; i < 10; i++ {
+		game, err := gamerules.NewGame("classic", types.BoardSize{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		variant, err := gamerules.GetVariant("classic")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var moves []types.PlayedMove
+		for variant.Outcome(game).Result == types.ResultOngoing {
+			move, err := ChooseMove(game, LevelPerfect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			moves = append(moves, types.PlayedMove{Ply: int64(len(moves) + 1), Placement: move})
+			game = variant.ApplyMove(game, move)
+		}
+		if result := variant.Outcome(game).Result; result != types.ResultDraw {
+			t.Fatalf("game %v ended with %d, want a draw", moves, result)
+		}
+
+		analyses, err := Analyze(game, moves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, analysis := range analyses {
+			if analysis.Blunder {
+				t.Errorf("move %+v of the perfect game %v is a blunder", analysis.PlayedMove, moves)
+			}
+		}
+	}
+}
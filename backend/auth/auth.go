@@ -81,6 +81,19 @@ func ValidateToken(tokenStr string) (*jwt.Token, error) {
 	return token, nil
 }
 
+// GetUsername returns the username stored in the token claims
+func GetUsername(token *jwt.Token) (string, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", errors.New("invalid token claims")
+	}
+	username, ok := claims["username"].(string)
+	if !ok || username == "" {
+		return "", errors.New("missing username in token")
+	}
+	return username, nil
+}
+
 // Authenticate middleware
 func Authenticate(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -100,7 +113,13 @@ func Authenticate(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), "user", token.Claims.(jwt.MapClaims)["username"])
+		username, err := GetUsername(token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "user", username)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
@@ -125,7 +144,11 @@ func RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := token.Claims.(jwt.MapClaims)["username"].(string)
+	username, err := GetUsername(token)
+	if err != nil {
+		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	}
 
 	// Generate new tokens
 	accessToken, err := GenerateAccessToken(username)
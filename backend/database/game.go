@@ -3,75 +3,215 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
 	"github.com/allanlepinay/TicTacToe/backend/types"
 )
 
-func GetGame(db *sql.DB, gameId int64) (types.Game, error) {
+const gameColumns = "id, variant, turn, status, result, player_x_id, player_o_id, width, height, win_length, rated"
+
+// GetGame returns the game with its board rebuilt from the moves
+func (s *PostgresStore) GetGame(gameId int64) (types.Game, error) {
+	game, err := getGame(s.db, "SELECT "+gameColumns+" FROM games WHERE id = $1", gameId)
+	if err != nil {
+		return types.Game{}, err
+	}
+	return loadGameState(s.db, game)
+}
+
+// getGameForUpdate locks the game row until the end of the transaction
+func getGameForUpdate(tx *sql.Tx, gameId int64) (types.Game, error) {
+	game, err := getGame(tx, "SELECT "+gameColumns+" FROM games WHERE id = $1 FOR UPDATE", gameId)
+	if err != nil {
+		return types.Game{}, err
+	}
+	return loadGameState(tx, game)
+}
+
+// getGame reads the game row only, without its board
+func getGame(db DBTX, query string, gameId int64) (types.Game, error) {
 	var game types.Game
-	err := db.QueryRow("SELECT id, turn, player_x_id, player_o_id FROM games WHERE id = $1", gameId).Scan(&game.ID, &game.Turn, &game.PlayerXId, &game.PlayerOId)
+	var statusString, resultString string
+	err := db.QueryRow(query, gameId).Scan(&game.ID, &game.Variant, &game.Turn, &statusString, &resultString, &game.PlayerXId, &game.PlayerOId,
+		&game.Width, &game.Height, &game.WinLength, &game.Rated)
+	if err != nil {
+		return types.Game{}, notFound(err)
+	}
+	game.Status, err = getStatusFromName(statusString)
+	if err != nil {
+		return types.Game{}, err
+	}
+	game.Result, err = getResultFromName(resultString)
 	if err != nil {
 		return types.Game{}, err
 	}
 	return game, nil
 }
 
-func UpdateGameStatus(db *sql.DB, gameId int64, status types.GameStatus) error {
-	_, err := db.Exec("UPDATE games SET status = $1, updated_at = $2 WHERE id = $3", types.StatusName[status], time.Now(), gameId)
+func (s *PostgresStore) GetGameDetails(gameId int64) (types.GameDetails, error) {
+	game, err := s.GetGame(gameId)
 	if err != nil {
-		fmt.Println(err)
-		return fmt.Errorf("failed to update game status: %w", err)
+		return types.GameDetails{}, err
 	}
-	return nil
+
+	details := types.GameDetails{Game: game}
+	query := `
+		SELECT games.created_at, games.updated_at, player_x.name, player_o.name,
+			(SELECT COUNT(*) FROM moves WHERE moves.game_id = games.id)
+		FROM games
+		JOIN players player_x ON player_x.id = games.player_x_id
+		JOIN players player_o ON player_o.id = games.player_o_id
+		WHERE games.id = $1
+	`
+	err = s.db.QueryRow(query, gameId).Scan(&details.CreatedAt, &details.UpdatedAt, &details.PlayerXName, &details.PlayerOName, &details.MoveCount)
+	if err != nil {
+		return types.GameDetails{}, notFound(err)
+	}
+	return details, nil
 }
 
-func CreateNewGame(db *sql.DB, player_x_name string, player_o_name string) (types.Game, error) {
-	playerO, _ := GetPlayerByName(db, player_o_name)
-	playerX, _ := GetPlayerByName(db, player_x_name)
+func (s *PostgresStore) ListGames(filter types.GameFilter) ([]types.GameDetails, int64, error) {
+	var conditions []string
+	var args []any
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Status != nil {
+		addCondition("games.status = $%d", types.StatusName[types.GameStatus(*filter.Status)])
+	}
+	if filter.Player != "" {
+		addCondition("(player_x.name = $%[1]d OR player_o.name = $%[1]d)", filter.Player)
+	}
+	if filter.Variant != "" {
+		addCondition("games.variant = $%d", filter.Variant)
+	}
+	if !filter.From.IsZero() {
+		addCondition("games.created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("games.created_at < $%d", filter.To)
+	}
+
+	from := `
+		FROM games
+		JOIN players player_x ON player_x.id = games.player_x_id
+		JOIN players player_o ON player_o.id = games.player_o_id
+	`
+	if len(conditions) > 0 {
+		from += " WHERE " + strings.Join(conditions, " AND ")
+	}
 
-	res, err := db.Query("INSERT INTO games (status, player_x_id, player_o_id) VALUES ($1, $2, $3) RETURNING id", types.StatusName[types.StatusStarted], playerX.ID, playerO.ID)
+	var total int64
+	err := s.db.QueryRow("SELECT COUNT(*) "+from, args...).Scan(&total)
 	if err != nil {
-		return types.Game{}, err
+		return nil, 0, fmt.Errorf("failed to count games: %w", err)
 	}
 
-	var id int64
-	for res.Next() {
-		err = res.Scan(&id)
+	query := `
+		SELECT games.id, games.variant, games.turn, games.status, games.result, games.player_x_id, games.player_o_id,
+			games.width, games.height, games.win_length, games.rated, games.created_at, games.updated_at, player_x.name, player_o.name,
+			(SELECT COUNT(*) FROM moves WHERE moves.game_id = games.id)
+	` + from + fmt.Sprintf(" ORDER BY games.created_at DESC, games.id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	rows, err := s.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []types.GameDetails
+	for rows.Next() {
+		var game types.GameDetails
+		var statusString, resultString string
+		err = rows.Scan(&game.ID, &game.Variant, &game.Turn, &statusString, &resultString, &game.PlayerXId, &game.PlayerOId,
+			&game.Width, &game.Height, &game.WinLength, &game.Rated, &game.CreatedAt, &game.UpdatedAt, &game.PlayerXName, &game.PlayerOName, &game.MoveCount)
+		if err != nil {
+			return nil, 0, err
+		}
+		game.Status, err = getStatusFromName(statusString)
 		if err != nil {
-			return types.Game{}, err
+			return nil, 0, err
 		}
+		game.Result, err = getResultFromName(resultString)
+		if err != nil {
+			return nil, 0, err
+		}
+		games = append(games, game)
 	}
+	return games, total, rows.Err()
+}
 
-	game := types.Game{
-		ID: id,
-		Board: [3][3]string{
-			{"", "", ""},
-			{"", "", ""},
-			{"", "", ""},
-		},
-		Turn:      "X",
-		Status:    types.StatusStarted,
-		PlayerXId: playerX.ID,
-		PlayerOId: playerO.ID,
+func getStatusFromName(statusString string) (int64, error) {
+	for status, name := range types.StatusName {
+		if name == statusString {
+			return int64(status), nil
+		}
 	}
+	return 0, fmt.Errorf("unknown game status: %s", statusString)
+}
 
-	return game, nil
+func getResultFromName(resultString string) (int64, error) {
+	for result, name := range types.ResultName {
+		if name == resultString {
+			return int64(result), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown game result: %s", resultString)
+}
+
+func updateGameStatus(db DBTX, gameId int64, status types.GameStatus) error {
+	_, err := db.Exec("UPDATE games SET status = $1, updated_at = $2 WHERE id = $3", types.StatusName[status], time.Now(), gameId)
+	if err != nil {
+		return fmt.Errorf("failed to update game status: %w", err)
+	}
+	return nil
 }
 
-func UpdateGameTurn(db *sql.DB, gameId int64) error {
-	_, err := db.Exec("UPDATE games SET turn = CASE WHEN turn = 'X' THEN 'O' ELSE 'X' END WHERE id = $1", gameId)
+func updateGameResult(db DBTX, gameId int64, result types.GameResult) error {
+	_, err := db.Exec("UPDATE games SET result = $1, updated_at = $2 WHERE id = $3", types.ResultName[result], time.Now(), gameId)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to update game result: %w", err)
 	}
 	return nil
 }
 
-func GetGameTurn(db *sql.DB, gameId int64) (string, error) {
-	var turn string
-	err := db.QueryRow("SELECT turn FROM games WHERE id = $1", gameId).Scan(&turn)
+func (s *PostgresStore) CreateNewGame(playerXName string, playerOName string, variant string, size types.BoardSize, rated bool) (types.Game, error) {
+	return createGame(s.db, playerXName, playerOName, variant, size, rated)
+}
+
+func createGame(db DBTX, playerXName string, playerOName string, variant string, size types.BoardSize, rated bool) (types.Game, error) {
+	game, err := gamerules.NewGame(variant, size)
 	if err != nil {
-		return "", err
+		return types.Game{}, err
+	}
+
+	playerX, err := getPlayerByName(db, playerXName)
+	if err != nil {
+		return types.Game{}, fmt.Errorf("failed to get player X: %w", err)
+	}
+	playerO, err := getPlayerByName(db, playerOName)
+	if err != nil {
+		return types.Game{}, fmt.Errorf("failed to get player O: %w", err)
+	}
+
+	game.PlayerXId = playerX.ID
+	game.PlayerOId = playerO.ID
+	game.Rated = rated
+	err = db.QueryRow("INSERT INTO games (status, variant, turn, player_x_id, player_o_id, width, height, win_length, rated) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+		types.StatusName[types.StatusStarted], game.Variant, game.Turn, playerX.ID, playerO.ID, game.Width, game.Height, game.WinLength, rated).Scan(&game.ID)
+	if err != nil {
+		return types.Game{}, err
 	}
-	return turn, nil
+
+	return game, nil
+}
+
+func updateGameTurn(db DBTX, gameId int64, turn string) error {
+	_, err := db.Exec("UPDATE games SET turn = $1 WHERE id = $2", turn, gameId)
+	if err != nil {
+		return err
+	}
+	return nil
 }
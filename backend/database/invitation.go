@@ -0,0 +1,232 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+	"github.com/allanlepinay/TicTacToe/backend/utils"
+	"github.com/lib/pq"
+)
+
+// Attempts to draw a code not used yet before giving up
+const inviteCodeAttempts = 5
+
+const invitationQuery = `
+	SELECT invitations.code, from_player.name, COALESCE(to_player.name, ''), invitations.variant,
+		invitations.width, invitations.height, invitations.win_length, invitations.rated, invitations.status,
+		COALESCE(invitations.game_id, 0), invitations.created_at, invitations.expires_at
+	FROM invitations
+	JOIN players from_player ON from_player.id = invitations.from_player_id
+	LEFT JOIN players to_player ON to_player.id = invitations.to_player_id
+`
+
+func (s *PostgresStore) CreateInvitation(fromName string, toName string, variant string, size types.BoardSize, rated bool, expiresAt time.Time) (types.Invitation, error) {
+	if variant == "" {
+		variant = gamerules.DefaultVariant
+	}
+	size, err := gamerules.BoardSizeOf(variant, size)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	from, err := s.GetPlayerByName(fromName)
+	if err != nil {
+		return types.Invitation{}, fmt.Errorf("failed to get inviting player: %w", err)
+	}
+	var toId sql.NullInt64
+	if toName != "" {
+		to, err := s.GetPlayerByName(toName)
+		if err != nil {
+			return types.Invitation{}, fmt.Errorf("failed to get invited player: %w", err)
+		}
+		toId = sql.NullInt64{Int64: to.ID, Valid: true}
+	}
+
+	for attempt := 0; attempt < inviteCodeAttempts; attempt++ {
+		code, err := utils.NewInviteCode()
+		if err != nil {
+			return types.Invitation{}, err
+		}
+		_, err = s.db.Exec(`INSERT INTO invitations (code, from_player_id, to_player_id, variant, width, height, win_length, rated, status, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			code, from.ID, toId, variant, size.Width, size.Height, size.WinLength, rated, types.InvitationStatusName[types.InvitationPending], expiresAt)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation, the code is taken
+			continue
+		}
+		if err != nil {
+			return types.Invitation{}, fmt.Errorf("failed to create invitation: %w", err)
+		}
+		return s.GetInvitation(code)
+	}
+	return types.Invitation{}, errors.New("failed to find an unused invite code")
+}
+
+func (s *PostgresStore) GetInvitation(code string) (types.Invitation, error) {
+	return getInvitation(s.db, invitationQuery+" WHERE invitations.code = $1", code)
+}
+
+func getInvitation(db DBTX, query string, code string) (types.Invitation, error) {
+	invitation, err := scanInvitation(db.QueryRow(query, code))
+	if err != nil {
+		return types.Invitation{}, notFound(err)
+	}
+	return invitation, nil
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanInvitation(row scanner) (types.Invitation, error) {
+	var invitation types.Invitation
+	var statusString string
+	err := row.Scan(&invitation.Code, &invitation.FromName, &invitation.ToName, &invitation.Variant,
+		&invitation.Width, &invitation.Height, &invitation.WinLength, &invitation.Rated, &statusString,
+		&invitation.GameId, &invitation.CreatedAt, &invitation.ExpiresAt)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+	invitation.Status, err = getInvitationStatusFromName(statusString)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+	return invitation, nil
+}
+
+func getInvitationStatusFromName(statusString string) (int64, error) {
+	for status, name := range types.InvitationStatusName {
+		if name == statusString {
+			return int64(status), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown invitation status: %s", statusString)
+}
+
+// checkAnswer returns why username can't answer the invitation at now, nil if it can
+func checkAnswer(invitation types.Invitation, username string, now time.Time) error {
+	if invitation.Status != types.InvitationPending || !now.Before(invitation.ExpiresAt) {
+		return ErrInvitationClosed
+	}
+	if username == invitation.FromName || (invitation.ToName != "" && username != invitation.ToName) {
+		return ErrNotInvited
+	}
+	return nil
+}
+
+func (s *PostgresStore) AcceptInvitation(code string, username string) (types.Invitation, types.Game, error) {
+	var invitation types.Invitation
+	var game types.Game
+	err := s.inTransaction(func(tx *sql.Tx) error {
+		var err error
+		// Locked so two players can't both join with the same code
+		invitation, err = getInvitation(tx, invitationQuery+" WHERE invitations.code = $1 FOR UPDATE OF invitations", code)
+		if err != nil {
+			return err
+		}
+		if err = checkAnswer(invitation, username, time.Now()); err != nil {
+			return err
+		}
+
+		game, err = createGame(tx, invitation.FromName, username, invitation.Variant, invitation.BoardSize, invitation.Rated)
+		if err != nil {
+			return err
+		}
+		invitation.Status = types.InvitationAccepted
+		invitation.GameId = game.ID
+		invitation.ToName = username
+		return updateInvitation(tx, invitation)
+	})
+	if err != nil {
+		return types.Invitation{}, types.Game{}, err
+	}
+	return invitation, game, nil
+}
+
+func (s *PostgresStore) DeclineInvitation(code string, username string) (types.Invitation, error) {
+	var invitation types.Invitation
+	err := s.inTransaction(func(tx *sql.Tx) error {
+		var err error
+		invitation, err = getInvitation(tx, invitationQuery+" WHERE invitations.code = $1 FOR UPDATE OF invitations", code)
+		if err != nil {
+			return err
+		}
+		if err = checkAnswer(invitation, username, time.Now()); err != nil {
+			return err
+		}
+
+		invitation.Status = types.InvitationDeclined
+		return updateInvitation(tx, invitation)
+	})
+	if err != nil {
+		return types.Invitation{}, err
+	}
+	return invitation, nil
+}
+
+// updateInvitation saves the status and the game of the invitation, and its player for invitations by code
+func updateInvitation(db DBTX, invitation types.Invitation) error {
+	var gameId sql.NullInt64
+	if invitation.GameId != 0 {
+		gameId = sql.NullInt64{Int64: invitation.GameId, Valid: true}
+	}
+	_, err := db.Exec(`
+		UPDATE invitations SET status = $1, game_id = $2, to_player_id = (SELECT id FROM players WHERE name = $3)
+		WHERE code = $4
+	`, types.InvitationStatusName[types.InvitationStatus(invitation.Status)], gameId, invitation.ToName, invitation.Code)
+	if err != nil {
+		return fmt.Errorf("failed to update invitation: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) GetPendingChallenges(username string) ([]types.Invitation, error) {
+	return queryInvitations(s.db, invitationQuery+" WHERE to_player.name = $1 AND invitations.status = $2 AND invitations.expires_at > $3 ORDER BY invitations.id",
+		username, types.InvitationStatusName[types.InvitationPending], time.Now())
+}
+
+func (s *PostgresStore) ExpireInvitations(now time.Time) ([]types.Invitation, error) {
+	var invitations []types.Invitation
+	err := s.inTransaction(func(tx *sql.Tx) error {
+		var err error
+		invitations, err = queryInvitations(tx, invitationQuery+" WHERE invitations.status = $1 AND invitations.expires_at <= $2 FOR UPDATE OF invitations",
+			types.InvitationStatusName[types.InvitationPending], now)
+		if err != nil {
+			return err
+		}
+		for i := range invitations {
+			invitations[i].Status = types.InvitationExpired
+			if err = updateInvitation(tx, invitations[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func queryInvitations(db DBTX, query string, args ...any) ([]types.Invitation, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []types.Invitation
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
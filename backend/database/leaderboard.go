@@ -0,0 +1,86 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// leaderboardQuery ranks the players of the finished rated games matching the conditions, the statuses and results
+// are literals so the partial indexes on finished rated games can be used
+func leaderboardQuery(conditions string) string {
+	return fmt.Sprintf(`
+		WITH finished AS (
+			SELECT player_x_id, player_o_id, result FROM games
+			WHERE status = '%[1]s' AND rated%[4]s
+		), results AS (
+			SELECT player_x_id AS player_id, result = '%[2]s' AS win, result = '%[3]s' AS loss FROM finished
+			UNION ALL
+			SELECT player_o_id, result = '%[3]s', result = '%[2]s' FROM finished
+		), standings AS (
+			SELECT players.name, players.rating,
+				COUNT(*) FILTER (WHERE win) AS wins,
+				COUNT(*) FILTER (WHERE loss) AS losses,
+				COUNT(*) FILTER (WHERE NOT win AND NOT loss) AS draws
+			FROM results
+			JOIN players ON players.id = results.player_id
+			GROUP BY players.id
+		), ranked AS (
+			SELECT standings.*, wins + draws * 0.5 AS score,
+				RANK() OVER (ORDER BY wins + draws * 0.5 DESC, rating DESC) AS rank,
+				ROW_NUMBER() OVER (ORDER BY wins + draws * 0.5 DESC, rating DESC, name) AS position
+			FROM standings
+		)
+	`, types.StatusName[types.StatusTerminated], types.ResultName[types.ResultXWins], types.ResultName[types.ResultOWins], conditions)
+}
+
+func (s *PostgresStore) GetLeaderboard(filter types.LeaderboardFilter) (types.Leaderboard, error) {
+	conditions := ""
+	var args []any
+	if filter.Variant != "" {
+		args = append(args, filter.Variant)
+		conditions += fmt.Sprintf(" AND variant = $%d", len(args))
+	}
+	// A finished game isn't updated anymore
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		conditions += fmt.Sprintf(" AND updated_at >= $%d", len(args))
+	}
+	query := leaderboardQuery(conditions)
+
+	var leaderboard types.Leaderboard
+	err := s.db.QueryRow(query+"SELECT COUNT(*) FROM ranked", args...).Scan(&leaderboard.Total)
+	if err != nil {
+		return types.Leaderboard{}, fmt.Errorf("failed to count leaderboard: %w", err)
+	}
+
+	// The page and the row of the player, which can be out of the page
+	n := len(args)
+	query += fmt.Sprintf(`
+		SELECT rank, name, rating, score, wins, losses, draws, position FROM ranked
+		WHERE (position > $%d AND position <= $%d) OR name = $%d
+		ORDER BY position
+	`, n+1, n+2, n+3)
+	rows, err := s.db.Query(query, append(args, filter.Offset, filter.Offset+filter.Limit, filter.Player)...)
+	if err != nil {
+		return types.Leaderboard{}, fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry types.LeaderboardEntry
+		var position int
+		err = rows.Scan(&entry.Rank, &entry.Name, &entry.Rating, &entry.Score, &entry.Wins, &entry.Losses, &entry.Draws, &position)
+		if err != nil {
+			return types.Leaderboard{}, err
+		}
+		if entry.Name == filter.Player {
+			me := entry
+			leaderboard.Me = &me
+		}
+		if position > filter.Offset && position <= filter.Offset+filter.Limit {
+			leaderboard.Entries = append(leaderboard.Entries, entry)
+		}
+	}
+	return leaderboard, rows.Err()
+}
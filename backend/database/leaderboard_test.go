@@ -0,0 +1,71 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+func TestMemoryStoreLeaderboardSince(t *testing.T) {
+	store := NewMemoryStore()
+	testLeaderboardSince(t, store, func(gameId int64, finishedAt time.Time) {
+		store.mutex.Lock()
+		defer store.mutex.Unlock()
+		store.games[gameId].updatedAt = finishedAt
+	})
+}
+
+func TestPostgresStoreLeaderboardSince(t *testing.T) {
+	store := openTestPostgresStore(t)
+	testLeaderboardSince(t, store, func(gameId int64, finishedAt time.Time) {
+		if _, err := store.db.Exec("UPDATE games SET updated_at = $1 WHERE id = $2", finishedAt, gameId); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// testLeaderboardSince finishes a game at the start of the period and one a second before, both in a zone other
+// than the one of the start: only the first one counts
+func testLeaderboardSince(t *testing.T, store Store, finish func(gameId int64, finishedAt time.Time)) {
+	suffix := time.Now().UnixNano()
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	zone := time.FixedZone("UTC+10", 10*60*60)
+
+	winners := make(map[time.Duration]string)
+	for _, before := range []time.Duration{0, time.Second} {
+		winner, loser := fmt.Sprintf("w%d-%d", before/time.Second, suffix), fmt.Sprintf("l%d-%d", before/time.Second, suffix)
+		for _, name := range []string{winner, loser} {
+			if err := store.CreatePlayer(name, "hash"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		game, err := store.CreateNewGame(winner, loser, gamerules.DefaultVariant, types.BoardSize{}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		game = play(t, store, game.ID, winner, loser, [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}})
+		if types.GameResult(game.Result) != types.ResultXWins {
+			t.Fatalf("game of %s ended with %d, want a win of X", winner, game.Result)
+		}
+		finish(game.ID, since.Add(-before).In(zone))
+		winners[before] = winner
+	}
+
+	leaderboard, err := store.GetLeaderboard(types.LeaderboardFilter{Since: since, Player: winners[0], Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaderboard.Me == nil || leaderboard.Me.Wins != 1 {
+		t.Errorf("standing of the game finished at the start = %+v, want 1 win", leaderboard.Me)
+	}
+	leaderboard, err = store.GetLeaderboard(types.LeaderboardFilter{Since: since, Player: winners[time.Second], Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaderboard.Me != nil {
+		t.Errorf("standing of the game finished before the start = %+v, want none", leaderboard.Me)
+	}
+}
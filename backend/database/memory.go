@@ -0,0 +1,767 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/ratings"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+	"github.com/allanlepinay/TicTacToe/backend/utils"
+)
+
+type memoryPlayer struct {
+	player        types.Player
+	passwordHash  string
+	ratingHistory []types.RatingChange
+}
+
+type memoryMove struct {
+	player    string
+	placement types.Placement
+	timestamp time.Time
+}
+
+type memoryGame struct {
+	game      types.Game
+	moves     []memoryMove
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+type memoryTournament struct {
+	tournament types.Tournament
+	// Status and result of the games are read from the games on access
+	games []types.TournamentGame
+}
+
+// MemoryStore is a Store keeping everything in memory, for tests and running without a database
+type MemoryStore struct {
+	mutex         sync.Mutex
+	players       map[int64]*memoryPlayer
+	playersByName map[string]*memoryPlayer
+	games         map[int64]*memoryGame
+	invitations   map[string]*types.Invitation
+	tournaments   map[int64]*memoryTournament
+	lastPlayerId  int64
+	lastGameId    int64
+	// Tournaments are numbered like games
+	lastTournamentId int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		players:       make(map[int64]*memoryPlayer),
+		playersByName: make(map[string]*memoryPlayer),
+		games:         make(map[int64]*memoryGame),
+		invitations:   make(map[string]*types.Invitation),
+		tournaments:   make(map[int64]*memoryTournament),
+	}
+}
+
+func (s *MemoryStore) CreatePlayer(name string, passwordHash string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.playersByName[name]; ok {
+		return ErrPlayerExists
+	}
+	s.lastPlayerId++
+	player := &memoryPlayer{
+		player:       types.Player{ID: s.lastPlayerId, Name: name, Rating: ratings.Default()},
+		passwordHash: passwordHash,
+	}
+	s.players[player.player.ID] = player
+	s.playersByName[name] = player
+	return nil
+}
+
+func (s *MemoryStore) GetPasswordHash(name string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	player, ok := s.playersByName[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return player.passwordHash, nil
+}
+
+func (s *MemoryStore) GetPlayerByName(name string) (types.Player, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	player, ok := s.playersByName[name]
+	if !ok {
+		return types.Player{}, ErrNotFound
+	}
+	return types.Player{ID: player.player.ID, Name: player.player.Name, Rating: player.player.Rating}, nil
+}
+
+func (s *MemoryStore) GetPlayerProfile(playerId string) (types.PlayerProfile, error) {
+	id, err := strconv.ParseInt(playerId, 10, 64)
+	if err != nil {
+		return types.PlayerProfile{}, ErrNotFound
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	player, ok := s.players[id]
+	if !ok {
+		return types.PlayerProfile{}, ErrNotFound
+	}
+
+	profile := types.PlayerProfile{
+		Player:        player.player,
+		RatingHistory: append([]types.RatingChange(nil), player.ratingHistory...),
+	}
+	var games []*memoryGame
+	for _, game := range s.games {
+		if game.game.PlayerXId == id || game.game.PlayerOId == id {
+			games = append(games, game)
+		}
+	}
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].updatedAt.After(games[j].updatedAt)
+	})
+	for _, game := range games {
+		profile.Games = append(profile.Games, types.Game{
+			ID:        game.game.ID,
+			Status:    game.game.Status,
+			Result:    game.game.Result,
+			PlayerXId: game.game.PlayerXId,
+			PlayerOId: game.game.PlayerOId,
+		})
+	}
+	setProfileStats(&profile)
+
+	return profile, nil
+}
+
+func (s *MemoryStore) CreateNewGame(playerXName string, playerOName string, variant string, size types.BoardSize, rated bool) (types.Game, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.createGame(playerXName, playerOName, variant, size, rated)
+}
+
+// createGame must be called with the mutex held
+func (s *MemoryStore) createGame(playerXName string, playerOName string, variant string, size types.BoardSize, rated bool) (types.Game, error) {
+	game, err := gamerules.NewGame(variant, size)
+	if err != nil {
+		return types.Game{}, err
+	}
+
+	playerX, ok := s.playersByName[playerXName]
+	if !ok {
+		return types.Game{}, fmt.Errorf("failed to get player X: %w", ErrNotFound)
+	}
+	playerO, ok := s.playersByName[playerOName]
+	if !ok {
+		return types.Game{}, fmt.Errorf("failed to get player O: %w", ErrNotFound)
+	}
+
+	s.lastGameId++
+	now := time.Now()
+	game.ID = s.lastGameId
+	game.PlayerXId = playerX.player.ID
+	game.PlayerOId = playerO.player.ID
+	game.Rated = rated
+	s.games[game.ID] = &memoryGame{
+		game:      game,
+		createdAt: now,
+		updatedAt: now,
+	}
+	game.Board = gamerules.CopyBoard(game.Board)
+	return game, nil
+}
+
+func (s *MemoryStore) GetGame(gameId int64) (types.Game, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	game, ok := s.games[gameId]
+	if !ok {
+		return types.Game{}, ErrNotFound
+	}
+	result := game.game
+	result.Board = gamerules.CopyBoard(result.Board)
+	return result, nil
+}
+
+func (s *MemoryStore) GetGameDetails(gameId int64) (types.GameDetails, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	game, ok := s.games[gameId]
+	if !ok {
+		return types.GameDetails{}, ErrNotFound
+	}
+	details := types.GameDetails{
+		Game:        game.game,
+		PlayerXName: s.players[game.game.PlayerXId].player.Name,
+		PlayerOName: s.players[game.game.PlayerOId].player.Name,
+		MoveCount:   int64(len(game.moves)),
+		CreatedAt:   game.createdAt,
+		UpdatedAt:   game.updatedAt,
+	}
+	details.Board = gamerules.CopyBoard(details.Board)
+	return details, nil
+}
+
+func (s *MemoryStore) ListGames(filter types.GameFilter) ([]types.GameDetails, int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var matching []*memoryGame
+	for _, game := range s.games {
+		playerX := s.players[game.game.PlayerXId].player.Name
+		playerO := s.players[game.game.PlayerOId].player.Name
+		switch {
+		case filter.Status != nil && game.game.Status != *filter.Status:
+		case filter.Player != "" && filter.Player != playerX && filter.Player != playerO:
+		case filter.Variant != "" && filter.Variant != game.game.Variant:
+		case !filter.From.IsZero() && game.createdAt.Before(filter.From):
+		case !filter.To.IsZero() && !game.createdAt.Before(filter.To):
+		default:
+			matching = append(matching, game)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if !matching[i].createdAt.Equal(matching[j].createdAt) {
+			return matching[i].createdAt.After(matching[j].createdAt)
+		}
+		return matching[i].game.ID > matching[j].game.ID
+	})
+
+	var games []types.GameDetails
+	for i := filter.Offset; i < len(matching) && i < filter.Offset+filter.Limit; i++ {
+		game := matching[i]
+		details := types.GameDetails{
+			Game:        game.game,
+			PlayerXName: s.players[game.game.PlayerXId].player.Name,
+			PlayerOName: s.players[game.game.PlayerOId].player.Name,
+			MoveCount:   int64(len(game.moves)),
+			CreatedAt:   game.createdAt,
+			UpdatedAt:   game.updatedAt,
+		}
+		// Like the Postgres store, boards aren't listed
+		details.Board = nil
+		details.MetaBoard = nil
+		details.ActiveBoard = nil
+		details.WinningLine = nil
+		games = append(games, details)
+	}
+	return games, int64(len(matching)), nil
+}
+
+func (s *MemoryStore) GetMoves(gameId int64) ([]types.PlayedMove, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	game, ok := s.games[gameId]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	var moves []types.PlayedMove
+	for i, move := range game.moves {
+		moves = append(moves, types.PlayedMove{
+			Ply:       int64(i + 1),
+			Player:    move.player,
+			Placement: move.placement,
+			Timestamp: move.timestamp,
+		})
+	}
+	return moves, nil
+}
+
+func (s *MemoryStore) MakeMove(move types.Move) (types.Game, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	player, ok := s.playersByName[move.Username]
+	if !ok {
+		return types.Game{}, fmt.Errorf("failed to get player: %w", ErrNotFound)
+	}
+	gameMemory, ok := s.games[move.GameId]
+	if !ok {
+		return types.Game{}, fmt.Errorf("failed to get game: %w", ErrNotFound)
+	}
+
+	game, outcome, err := gamerules.Play(gameMemory.game, player.player.ID, move.Placement)
+	if err != nil {
+		return types.Game{}, err
+	}
+
+	now := time.Now()
+	gameMemory.moves = append(gameMemory.moves, memoryMove{
+		player:    gameMemory.game.Turn,
+		placement: move.Placement,
+		timestamp: now,
+	})
+	gameMemory.game = game
+	game.Board = gamerules.CopyBoard(game.Board)
+	gameMemory.updatedAt = now
+
+	if game.Rated && outcome.Result != types.ResultOngoing {
+		playerX, playerO := s.players[game.PlayerXId], s.players[game.PlayerOId]
+		playerX.player.Rating, playerO.player.Rating = ratings.UpdateGame(playerX.player.Rating, playerO.player.Rating, outcome.Result)
+		for _, player := range []*memoryPlayer{playerX, playerO} {
+			player.ratingHistory = append(player.ratingHistory, types.RatingChange{GameId: game.ID, Rating: player.player.Rating, CreatedAt: now})
+		}
+	}
+
+	switch outcome.Result {
+	case types.ResultDraw:
+		s.players[game.PlayerXId].player.Draw++
+		s.players[game.PlayerOId].player.Draw++
+	case types.ResultXWins:
+		s.players[game.PlayerXId].player.Wins++
+		s.players[game.PlayerOId].player.Loses++
+	case types.ResultOWins:
+		s.players[game.PlayerOId].player.Wins++
+		s.players[game.PlayerXId].player.Loses++
+	}
+
+	return game, nil
+}
+
+func (s *MemoryStore) CreateInvitation(fromName string, toName string, variant string, size types.BoardSize, rated bool, expiresAt time.Time) (types.Invitation, error) {
+	if variant == "" {
+		variant = gamerules.DefaultVariant
+	}
+	size, err := gamerules.BoardSizeOf(variant, size)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.playersByName[fromName]; !ok {
+		return types.Invitation{}, fmt.Errorf("failed to get inviting player: %w", ErrNotFound)
+	}
+	if _, ok := s.playersByName[toName]; toName != "" && !ok {
+		return types.Invitation{}, fmt.Errorf("failed to get invited player: %w", ErrNotFound)
+	}
+
+	for attempt := 0; attempt < inviteCodeAttempts; attempt++ {
+		code, err := utils.NewInviteCode()
+		if err != nil {
+			return types.Invitation{}, err
+		}
+		if _, ok := s.invitations[code]; ok {
+			continue
+		}
+		invitation := &types.Invitation{
+			Code:      code,
+			FromName:  fromName,
+			ToName:    toName,
+			Variant:   variant,
+			BoardSize: size,
+			Rated:     rated,
+			Status:    types.InvitationPending,
+			CreatedAt: time.Now(),
+			ExpiresAt: expiresAt,
+		}
+		s.invitations[code] = invitation
+		return *invitation, nil
+	}
+	return types.Invitation{}, errors.New("failed to find an unused invite code")
+}
+
+func (s *MemoryStore) GetInvitation(code string) (types.Invitation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	invitation, ok := s.invitations[code]
+	if !ok {
+		return types.Invitation{}, ErrNotFound
+	}
+	return *invitation, nil
+}
+
+func (s *MemoryStore) AcceptInvitation(code string, username string) (types.Invitation, types.Game, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	invitation, ok := s.invitations[code]
+	if !ok {
+		return types.Invitation{}, types.Game{}, ErrNotFound
+	}
+	if err := checkAnswer(*invitation, username, time.Now()); err != nil {
+		return types.Invitation{}, types.Game{}, err
+	}
+
+	game, err := s.createGame(invitation.FromName, username, invitation.Variant, invitation.BoardSize, invitation.Rated)
+	if err != nil {
+		return types.Invitation{}, types.Game{}, err
+	}
+	invitation.Status = types.InvitationAccepted
+	invitation.GameId = game.ID
+	invitation.ToName = username
+	return *invitation, game, nil
+}
+
+func (s *MemoryStore) DeclineInvitation(code string, username string) (types.Invitation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	invitation, ok := s.invitations[code]
+	if !ok {
+		return types.Invitation{}, ErrNotFound
+	}
+	if err := checkAnswer(*invitation, username, time.Now()); err != nil {
+		return types.Invitation{}, err
+	}
+	invitation.Status = types.InvitationDeclined
+	return *invitation, nil
+}
+
+func (s *MemoryStore) GetPendingChallenges(username string) ([]types.Invitation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	var challenges []types.Invitation
+	for _, invitation := range s.invitations {
+		if invitation.ToName == username && invitation.Status == types.InvitationPending && now.Before(invitation.ExpiresAt) {
+			challenges = append(challenges, *invitation)
+		}
+	}
+	sort.Slice(challenges, func(i, j int) bool {
+		return challenges[i].CreatedAt.Before(challenges[j].CreatedAt)
+	})
+	return challenges, nil
+}
+
+func (s *MemoryStore) ExpireInvitations(now time.Time) ([]types.Invitation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var expired []types.Invitation
+	for _, invitation := range s.invitations {
+		if invitation.Status == types.InvitationPending && !now.Before(invitation.ExpiresAt) {
+			invitation.Status = types.InvitationExpired
+			expired = append(expired, *invitation)
+		}
+	}
+	return expired, nil
+}
+
+func (s *MemoryStore) GetLeaderboard(filter types.LeaderboardFilter) (types.Leaderboard, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	standings := make(map[int64]*types.LeaderboardEntry)
+	addResult := func(playerId int64, score float64) {
+		entry, ok := standings[playerId]
+		if !ok {
+			player := s.players[playerId].player
+			entry = &types.LeaderboardEntry{Name: player.Name, Rating: player.Rating.Rating}
+			standings[playerId] = entry
+		}
+		entry.Score += score
+		switch score {
+		case 1:
+			entry.Wins++
+		case 0:
+			entry.Losses++
+		default:
+			entry.Draws++
+		}
+	}
+	for _, game := range s.games {
+		switch {
+		case game.game.Status != int64(types.StatusTerminated) || !game.game.Rated:
+		case filter.Variant != "" && filter.Variant != game.game.Variant:
+		case !filter.Since.IsZero() && game.updatedAt.Before(filter.Since):
+		default:
+			var scoreX float64
+			switch types.GameResult(game.game.Result) {
+			case types.ResultXWins:
+				scoreX = 1
+			case types.ResultDraw:
+				scoreX = 0.5
+			}
+			addResult(game.game.PlayerXId, scoreX)
+			addResult(game.game.PlayerOId, 1-scoreX)
+		}
+	}
+
+	entries := make([]types.LeaderboardEntry, 0, len(standings))
+	for _, entry := range standings {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		if entries[i].Rating != entries[j].Rating {
+			return entries[i].Rating > entries[j].Rating
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	leaderboard := types.Leaderboard{Total: int64(len(entries))}
+	for i := range entries {
+		if i > 0 && entries[i].Score == entries[i-1].Score && entries[i].Rating == entries[i-1].Rating {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = int64(i + 1)
+		}
+		if entries[i].Name == filter.Player {
+			me := entries[i]
+			leaderboard.Me = &me
+		}
+	}
+	for i := filter.Offset; i < len(entries) && i < filter.Offset+filter.Limit; i++ {
+		leaderboard.Entries = append(leaderboard.Entries, entries[i])
+	}
+	return leaderboard, nil
+}
+
+func (s *MemoryStore) CreateTournament(name string, creatorName string, format string, variant string, size types.BoardSize, rated bool) (types.Tournament, error) {
+	if variant == "" {
+		variant = gamerules.DefaultVariant
+	}
+	size, err := gamerules.BoardSizeOf(variant, size)
+	if err != nil {
+		return types.Tournament{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.playersByName[creatorName]; !ok {
+		return types.Tournament{}, fmt.Errorf("failed to get tournament creator: %w", ErrNotFound)
+	}
+	s.lastTournamentId++
+	tournament := &memoryTournament{tournament: types.Tournament{
+		ID:        s.lastTournamentId,
+		Name:      name,
+		Format:    format,
+		Variant:   variant,
+		BoardSize: size,
+		Rated:     rated,
+		Status:    types.TournamentRegistration,
+		Creator:   creatorName,
+		Players:   []string{},
+		CreatedAt: time.Now(),
+	}}
+	s.tournaments[tournament.tournament.ID] = tournament
+	return copyTournament(tournament), nil
+}
+
+// copyTournament must be called with the mutex held
+func copyTournament(tournament *memoryTournament) types.Tournament {
+	copied := tournament.tournament
+	copied.Players = append([]string{}, tournament.tournament.Players...)
+	return copied
+}
+
+func (s *MemoryStore) GetTournament(tournamentId int64) (types.Tournament, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tournament, ok := s.tournaments[tournamentId]
+	if !ok {
+		return types.Tournament{}, ErrNotFound
+	}
+	return copyTournament(tournament), nil
+}
+
+func (s *MemoryStore) ListTournaments() ([]types.Tournament, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tournaments := make([]types.Tournament, 0, len(s.tournaments))
+	for _, tournament := range s.tournaments {
+		tournaments = append(tournaments, copyTournament(tournament))
+	}
+	sort.Slice(tournaments, func(i, j int) bool {
+		return tournaments[i].ID > tournaments[j].ID
+	})
+	return tournaments, nil
+}
+
+func (s *MemoryStore) JoinTournament(tournamentId int64, username string) (types.Tournament, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tournament, ok := s.tournaments[tournamentId]
+	if !ok {
+		return types.Tournament{}, ErrNotFound
+	}
+	if tournament.tournament.Status != types.TournamentRegistration {
+		return types.Tournament{}, ErrRegistrationClosed
+	}
+	if _, ok := s.playersByName[username]; !ok {
+		return types.Tournament{}, fmt.Errorf("failed to get player: %w", ErrNotFound)
+	}
+	for _, player := range tournament.tournament.Players {
+		if player == username {
+			return types.Tournament{}, ErrAlreadyRegistered
+		}
+	}
+	tournament.tournament.Players = append(tournament.tournament.Players, username)
+	return copyTournament(tournament), nil
+}
+
+func (s *MemoryStore) LeaveTournament(tournamentId int64, username string) (types.Tournament, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tournament, ok := s.tournaments[tournamentId]
+	if !ok {
+		return types.Tournament{}, ErrNotFound
+	}
+	if tournament.tournament.Status != types.TournamentRegistration {
+		return types.Tournament{}, ErrRegistrationClosed
+	}
+	for i, player := range tournament.tournament.Players {
+		if player == username {
+			tournament.tournament.Players = append(tournament.tournament.Players[:i], tournament.tournament.Players[i+1:]...)
+			return copyTournament(tournament), nil
+		}
+	}
+	return types.Tournament{}, ErrNotRegistered
+}
+
+func (s *MemoryStore) StartTournament(tournamentId int64, seeds []string) (types.Tournament, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tournament, ok := s.tournaments[tournamentId]
+	if !ok {
+		return types.Tournament{}, ErrNotFound
+	}
+	if tournament.tournament.Status != types.TournamentRegistration {
+		return types.Tournament{}, ErrRegistrationClosed
+	}
+	// Like the Postgres store, registered players missing from the seeds are last
+	seeded := append([]string{}, seeds...)
+	for _, player := range tournament.tournament.Players {
+		found := false
+		for _, seed := range seeds {
+			found = found || seed == player
+		}
+		if !found {
+			seeded = append(seeded, player)
+		}
+	}
+	tournament.tournament.Players = seeded
+	tournament.tournament.Status = types.TournamentInProgress
+	return copyTournament(tournament), nil
+}
+
+func (s *MemoryStore) CreateTournamentGames(tournamentId int64, pairings []types.TournamentPairing) ([]types.TournamentGame, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tournament, ok := s.tournaments[tournamentId]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if tournament.tournament.Status != types.TournamentInProgress {
+		return nil, ErrTournamentNotActive
+	}
+
+	// Every pairing is checked before creating any game so a round is never half created, like in the transaction
+	// of the Postgres store
+	type key struct{ round, board, replay int }
+	existing := make(map[key]bool)
+	for _, game := range tournament.games {
+		existing[key{game.Round, game.Board, game.Replay}] = true
+	}
+	for _, pairing := range pairings {
+		if existing[key{pairing.Round, pairing.Board, pairing.Replay}] {
+			return nil, fmt.Errorf("failed to create tournament game: round %d board %d replay %d already exists", pairing.Round, pairing.Board, pairing.Replay)
+		}
+		existing[key{pairing.Round, pairing.Board, pairing.Replay}] = true
+		if _, ok := s.playersByName[pairing.PlayerX]; !ok {
+			return nil, fmt.Errorf("failed to get player X: %w", ErrNotFound)
+		}
+		if _, ok := s.playersByName[pairing.PlayerO]; pairing.PlayerO != "" && !ok {
+			return nil, fmt.Errorf("failed to get player O: %w", ErrNotFound)
+		}
+	}
+
+	var games []types.TournamentGame
+	for _, pairing := range pairings {
+		game := types.TournamentGame{TournamentPairing: pairing, TournamentId: tournamentId}
+		if pairing.PlayerO != "" {
+			created, err := s.createGame(pairing.PlayerX, pairing.PlayerO, tournament.tournament.Variant, tournament.tournament.BoardSize, tournament.tournament.Rated)
+			if err != nil {
+				return nil, err
+			}
+			game.GameId = created.ID
+			game.Status = created.Status
+			game.Result = created.Result
+		}
+		tournament.games = append(tournament.games, game)
+		games = append(games, game)
+		tournament.tournament.Round = max(tournament.tournament.Round, pairing.Round)
+	}
+	sort.SliceStable(tournament.games, func(i, j int) bool {
+		a, b := tournament.games[i], tournament.games[j]
+		if a.Round != b.Round {
+			return a.Round < b.Round
+		}
+		if a.Board != b.Board {
+			return a.Board < b.Board
+		}
+		return a.Replay < b.Replay
+	})
+	return games, nil
+}
+
+func (s *MemoryStore) GetTournamentGames(tournamentId int64) ([]types.TournamentGame, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tournament, ok := s.tournaments[tournamentId]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	games := make([]types.TournamentGame, len(tournament.games))
+	for i, game := range tournament.games {
+		if game.GameId != 0 {
+			game.Status = s.games[game.GameId].game.Status
+			game.Result = s.games[game.GameId].game.Result
+		}
+		games[i] = game
+	}
+	return games, nil
+}
+
+func (s *MemoryStore) GetTournamentOfGame(gameId int64) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, tournament := range s.tournaments {
+		for _, game := range tournament.games {
+			if game.GameId == gameId {
+				return tournament.tournament.ID, nil
+			}
+		}
+	}
+	return 0, ErrNotFound
+}
+
+func (s *MemoryStore) FinishTournament(tournamentId int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tournament, ok := s.tournaments[tournamentId]
+	if !ok {
+		return ErrNotFound
+	}
+	tournament.tournament.Status = types.TournamentFinished
+	return nil
+}
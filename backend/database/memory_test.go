@@ -0,0 +1,272 @@
+package database
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// play makes the moves in turn, x and o moving alternately from x
+func play(t *testing.T, store Store, gameId int64, x string, o string, cells [][2]int) types.Game {
+	t.Helper()
+	var game types.Game
+	for i, cell := range cells {
+		username := x
+		if i%2 == 1 {
+			username = o
+		}
+		var err error
+		game, err = store.MakeMove(types.Move{
+			WebsocketMessage: types.WebsocketMessage{Username: username, GameId: gameId},
+			Placement:        types.Placement{X: cell[0], Y: cell[1]},
+		})
+		if err != nil {
+			t.Fatalf("move %d of %s at %v: %v", i+1, username, cell, err)
+		}
+	}
+	return game
+}
+
+func TestMemoryStoreGames(t *testing.T) {
+	var store Store = NewMemoryStore()
+
+	for _, name := range []string{"alice", "bob"} {
+		if err := store.CreatePlayer(name, "hash-"+name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.CreatePlayer("alice", "other"); !errors.Is(err, ErrPlayerExists) {
+		t.Fatalf("second CreatePlayer = %v, want ErrPlayerExists", err)
+	}
+	if hash, err := store.GetPasswordHash("alice"); err != nil || hash != "hash-alice" {
+		t.Fatalf("GetPasswordHash = %q, %v", hash, err)
+	}
+
+	// Alice wins the first row
+	game, err := store.CreateNewGame("alice", "bob", gamerules.DefaultVariant, types.BoardSize{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game = play(t, store, game.ID, "alice", "bob", [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}})
+	if game.Status != types.StatusTerminated || types.GameResult(game.Result) != types.ResultXWins {
+		t.Fatalf("game status %d result %d, want X to win", game.Status, game.Result)
+	}
+	if len(game.WinningLine) != 3 {
+		t.Errorf("winning line = %v, want the 3 cells of the first row", game.WinningLine)
+	}
+	_, err = store.MakeMove(types.Move{
+		WebsocketMessage: types.WebsocketMessage{Username: "bob", GameId: game.ID},
+		Placement:        types.Placement{X: 2, Y: 2},
+	})
+	if !errors.Is(err, gamerules.ErrGameFinished) {
+		t.Fatalf("move after the end = %v, want ErrGameFinished", err)
+	}
+
+	stored, err := store.GetGame(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Board[0][2] != "X" || stored.Board[1][1] != "O" {
+		t.Errorf("stored board = %v", stored.Board)
+	}
+	moves, err := store.GetMoves(game.ID)
+	if err != nil || len(moves) != 5 {
+		t.Fatalf("GetMoves = %d moves, %v, want 5", len(moves), err)
+	}
+
+	// Then a draw, bob playing X
+	game, err = store.CreateNewGame("bob", "alice", gamerules.DefaultVariant, types.BoardSize{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game = play(t, store, game.ID, "bob", "alice", [][2]int{{0, 0}, {1, 1}, {2, 2}, {0, 2}, {2, 0}, {1, 0}, {1, 2}, {2, 1}, {0, 1}})
+	if game.Status != types.StatusTerminated || types.GameResult(game.Result) != types.ResultDraw {
+		t.Fatalf("game status %d result %d, want a draw", game.Status, game.Result)
+	}
+
+	alice, err := store.GetPlayerByName("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := store.GetPlayerByName("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alice.Rating.Rating <= bob.Rating.Rating {
+		t.Errorf("ratings alice %v bob %v, want alice above", alice.Rating.Rating, bob.Rating.Rating)
+	}
+
+	profile, err := store.GetPlayerProfile(strconv.FormatInt(alice.ID, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Wins != 1 || profile.Loses != 0 || profile.Draw != 1 {
+		t.Errorf("alice stats %d/%d/%d, want 1 win, 0 loss, 1 draw", profile.Wins, profile.Loses, profile.Draw)
+	}
+	if len(profile.Games) != 2 || profile.Games[0].ID != game.ID {
+		t.Fatalf("profile games = %v, want both games, the draw first", profile.Games)
+	}
+	if profile.WinRate != 0.5 {
+		t.Errorf("win rate = %v, want 0.5", profile.WinRate)
+	}
+	if profile.Streak != (types.Streak{Result: types.StreakDraw, Count: 1}) {
+		t.Errorf("streak = %+v, want 1 draw", profile.Streak)
+	}
+	if len(profile.RatingHistory) != 2 || profile.RatingHistory[1].Rating != alice.Rating {
+		t.Errorf("rating history = %+v, want 2 changes ending at %+v", profile.RatingHistory, alice.Rating)
+	}
+
+	profile, err = store.GetPlayerProfile(strconv.FormatInt(bob.ID, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Wins != 0 || profile.Loses != 1 || profile.Draw != 1 {
+		t.Errorf("bob stats %d/%d/%d, want 0 win, 1 loss, 1 draw", profile.Wins, profile.Loses, profile.Draw)
+	}
+	if profile.Streak != (types.Streak{Result: types.StreakDraw, Count: 1}) {
+		t.Errorf("streak = %+v, want 1 draw", profile.Streak)
+	}
+
+	if _, err = store.GetPlayerProfile("999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("profile of an unknown player = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryStoreBoardSizes(t *testing.T) {
+	var store Store = NewMemoryStore()
+	for _, name := range []string{"alice", "bob"} {
+		if err := store.CreatePlayer(name, "hash"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	size := types.BoardSize{Width: 4, Height: 4, WinLength: 3}
+
+	invitation, err := store.CreateInvitation("alice", "bob", "classic", size, false, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invitation.BoardSize != size {
+		t.Errorf("invitation board = %+v, want %+v", invitation.BoardSize, size)
+	}
+	_, game, err := store.AcceptInvitation(invitation.Code, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.BoardSize != size || len(game.Board) != 4 {
+		t.Errorf("game of the invitation on %+v with %d rows, want %+v", game.BoardSize, len(game.Board), size)
+	}
+
+	// The size of the variant when zero
+	invitation, err = store.CreateInvitation("alice", "", "gomoku", types.BoardSize{}, false, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invitation.BoardSize != gamerules.Gomoku {
+		t.Errorf("invitation board = %+v, want %+v", invitation.BoardSize, gamerules.Gomoku)
+	}
+
+	for _, invalid := range []struct {
+		variant string
+		size    types.BoardSize
+	}{
+		{"classic", types.BoardSize{Width: 3, Height: 3, WinLength: 4}},
+		{"classic", types.BoardSize{Width: 20, Height: 20, WinLength: 5}},
+		{"ultimate", size},
+	} {
+		if _, err = store.CreateInvitation("alice", "", invalid.variant, invalid.size, false, time.Now().Add(time.Minute)); err == nil {
+			t.Errorf("invitation of %s on %+v created", invalid.variant, invalid.size)
+		}
+		if _, err = store.CreateTournament("cup", "alice", "round-robin", invalid.variant, invalid.size, false); err == nil {
+			t.Errorf("tournament of %s on %+v created", invalid.variant, invalid.size)
+		}
+	}
+
+	tournament, err := store.CreateTournament("cup", "alice", "round-robin", "classic", size, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tournament.BoardSize != size {
+		t.Errorf("tournament board = %+v, want %+v", tournament.BoardSize, size)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		if _, err = store.JoinTournament(tournament.ID, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = store.StartTournament(tournament.ID, []string{"alice", "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	games, err := store.CreateTournamentGames(tournament.ID, []types.TournamentPairing{{Round: 1, Board: 1, PlayerX: "alice", PlayerO: "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, err = store.GetGame(games[0].GameId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.BoardSize != size {
+		t.Errorf("tournament game board = %+v, want %+v", game.BoardSize, size)
+	}
+}
+
+func TestMemoryStoreTournamentGamesAllOrNothing(t *testing.T) {
+	var store Store = NewMemoryStore()
+	players := []string{"a", "b", "c", "d"}
+	for _, name := range players {
+		if err := store.CreatePlayer(name, "hash"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tournament, err := store.CreateTournament("cup", "a", "round-robin", "classic", types.BoardSize{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range players {
+		if _, err = store.JoinTournament(tournament.ID, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = store.StartTournament(tournament.ID, players); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pairings := range [][]types.TournamentPairing{
+		{{Round: 1, Board: 1, PlayerX: "a", PlayerO: "b"}, {Round: 1, Board: 2, PlayerX: "c", PlayerO: "unknown"}},
+		{{Round: 1, Board: 1, PlayerX: "a", PlayerO: "b"}, {Round: 1, Board: 2, PlayerX: "unknown"}},
+		{{Round: 1, Board: 1, PlayerX: "a", PlayerO: "b"}, {Round: 1, Board: 1, PlayerX: "c", PlayerO: "d"}},
+	} {
+		if _, err = store.CreateTournamentGames(tournament.ID, pairings); err == nil {
+			t.Errorf("created the games of %+v", pairings)
+		}
+		games, err := store.GetTournamentGames(tournament.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(games) != 0 {
+			t.Fatalf("games %+v left by a failed round", games)
+		}
+		if _, err = store.GetGame(1); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("game created by a failed round: %v", err)
+		}
+	}
+
+	pairings := []types.TournamentPairing{{Round: 1, Board: 1, PlayerX: "a", PlayerO: "b"}, {Round: 1, Board: 2, PlayerX: "c", PlayerO: "d"}}
+	if _, err = store.CreateTournamentGames(tournament.ID, pairings); err != nil {
+		t.Fatal(err)
+	}
+	// Only the new pairing would be valid
+	pairings = []types.TournamentPairing{{Round: 2, Board: 1, PlayerX: "a", PlayerO: "c"}, {Round: 1, Board: 2, PlayerX: "b", PlayerO: "d"}}
+	if _, err = store.CreateTournamentGames(tournament.ID, pairings); err == nil {
+		t.Error("created a pairing twice")
+	}
+	if games, _ := store.GetTournamentGames(tournament.ID); len(games) != 2 {
+		t.Errorf("%d games after a failed round, want the 2 of the first one", len(games))
+	}
+	if tournament, _ = store.GetTournament(tournament.ID); tournament.Round != 1 {
+		t.Errorf("round %d after a failed round, want 1", tournament.Round)
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE player_games;
+DROP TABLE moves;
+DROP TABLE games;
+DROP TABLE players;
\ No newline at end of file
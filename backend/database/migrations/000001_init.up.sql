@@ -17,7 +17,7 @@ CREATE TABLE moves (
 
 CREATE TABLE players (
     id SERIAL PRIMARY KEY,
-    name VARCHAR(100) NOT NULL,
+    name VARCHAR(100) NOT NULL
 );
 
 CREATE TABLE player_games (
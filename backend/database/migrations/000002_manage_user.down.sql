@@ -0,0 +1,22 @@
+ALTER TABLE moves
+DROP CONSTRAINT unique_move;
+
+ALTER TABLE moves
+ADD CONSTRAINT unique_move UNIQUE (game_id, x, y, timestamp);
+
+ALTER TABLE games
+DROP COLUMN player_x_id,
+DROP COLUMN player_o_id;
+
+DROP INDEX idx_players_name;
+
+ALTER TABLE players
+DROP CONSTRAINT unique_name;
+
+ALTER TABLE players
+DROP COLUMN wins,
+DROP COLUMN losses,
+DROP COLUMN draws;
+
+ALTER TABLE players
+DROP COLUMN password_hash;
\ No newline at end of file
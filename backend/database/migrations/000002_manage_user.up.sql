@@ -5,6 +5,8 @@ ALTER TABLE players
 ADD COLUMN wins INTEGER DEFAULT 0,
 ADD COLUMN losses INTEGER DEFAULT 0,
 ADD COLUMN draws INTEGER DEFAULT 0;
+
+ALTER TABLE players
 ADD CONSTRAINT unique_name UNIQUE (name);
 
 CREATE INDEX idx_players_name ON players(name);
@@ -0,0 +1,2 @@
+ALTER TABLE games
+DROP COLUMN turn;
\ No newline at end of file
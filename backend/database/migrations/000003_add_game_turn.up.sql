@@ -0,0 +1,2 @@
+ALTER TABLE games
+ADD COLUMN turn CHAR(1) NOT NULL DEFAULT 'X';
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE players
+ADD COLUMN websocket_conn TEXT;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE players
+DROP COLUMN websocket_conn;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE games
+DROP COLUMN result;
\ No newline at end of file
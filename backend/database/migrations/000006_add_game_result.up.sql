@@ -0,0 +1,2 @@
+ALTER TABLE games
+ADD COLUMN result VARCHAR(20) NOT NULL DEFAULT 'Ongoing';
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE games
+DROP COLUMN width,
+DROP COLUMN height,
+DROP COLUMN win_length;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE games
+ADD COLUMN width INTEGER NOT NULL DEFAULT 3,
+ADD COLUMN height INTEGER NOT NULL DEFAULT 3,
+ADD COLUMN win_length INTEGER NOT NULL DEFAULT 3;
\ No newline at end of file
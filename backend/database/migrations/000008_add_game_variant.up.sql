@@ -0,0 +1,2 @@
+ALTER TABLE games
+ADD COLUMN variant VARCHAR(30) NOT NULL DEFAULT 'classic';
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP INDEX idx_games_status_created_at;
+DROP INDEX idx_games_created_at;
+DROP INDEX idx_games_player_x_id;
+DROP INDEX idx_games_player_o_id;
\ No newline at end of file
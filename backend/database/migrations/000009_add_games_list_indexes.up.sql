@@ -0,0 +1,4 @@
+CREATE INDEX idx_games_status_created_at ON games(status, created_at DESC);
+CREATE INDEX idx_games_created_at ON games(created_at DESC);
+CREATE INDEX idx_games_player_x_id ON games(player_x_id);
+CREATE INDEX idx_games_player_o_id ON games(player_o_id);
\ No newline at end of file
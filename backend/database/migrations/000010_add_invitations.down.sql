@@ -0,0 +1 @@
+DROP TABLE invitations;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE invitations (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(16) NOT NULL UNIQUE,
+    from_player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    to_player_id INTEGER REFERENCES players(id) ON DELETE CASCADE,
+    variant VARCHAR(32) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    win_length INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    game_id INTEGER REFERENCES games(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_invitations_to_player_id_status ON invitations(to_player_id, status);
+CREATE INDEX idx_invitations_status_expires_at ON invitations(status, expires_at);
\ No newline at end of file
@@ -0,0 +1,12 @@
+DROP TABLE rating_history;
+
+ALTER TABLE invitations
+DROP COLUMN rated;
+
+ALTER TABLE games
+DROP COLUMN rated;
+
+ALTER TABLE players
+DROP COLUMN rating,
+DROP COLUMN rating_deviation,
+DROP COLUMN rating_volatility;
\ No newline at end of file
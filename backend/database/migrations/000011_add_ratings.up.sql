@@ -0,0 +1,22 @@
+ALTER TABLE players
+ADD COLUMN rating DOUBLE PRECISION NOT NULL DEFAULT 1500,
+ADD COLUMN rating_deviation DOUBLE PRECISION NOT NULL DEFAULT 350,
+ADD COLUMN rating_volatility DOUBLE PRECISION NOT NULL DEFAULT 0.06;
+
+ALTER TABLE games
+ADD COLUMN rated BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE invitations
+ADD COLUMN rated BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE rating_history (
+    id SERIAL PRIMARY KEY,
+    player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    game_id INTEGER REFERENCES games(id) ON DELETE SET NULL,
+    rating DOUBLE PRECISION NOT NULL,
+    rating_deviation DOUBLE PRECISION NOT NULL,
+    rating_volatility DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_rating_history_player_id ON rating_history(player_id);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX idx_games_rated_finished_updated_at;
+DROP INDEX idx_games_rated_finished_variant_updated_at;
\ No newline at end of file
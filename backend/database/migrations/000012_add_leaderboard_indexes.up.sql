@@ -0,0 +1,2 @@
+CREATE INDEX idx_games_rated_finished_updated_at ON games(updated_at) WHERE rated AND status = 'Terminated';
+CREATE INDEX idx_games_rated_finished_variant_updated_at ON games(variant, updated_at) WHERE rated AND status = 'Terminated';
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE tournament_games;
+DROP TABLE tournament_players;
+DROP TABLE tournaments;
\ No newline at end of file
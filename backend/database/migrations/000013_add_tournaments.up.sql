@@ -0,0 +1,37 @@
+CREATE TABLE tournaments (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    format VARCHAR(32) NOT NULL,
+    variant VARCHAR(32) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    win_length INTEGER NOT NULL,
+    rated BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL,
+    creator_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE tournament_players (
+    tournament_id INTEGER REFERENCES tournaments(id) ON DELETE CASCADE,
+    player_id INTEGER REFERENCES players(id) ON DELETE CASCADE,
+    seed INTEGER,
+    joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tournament_id, player_id)
+);
+
+CREATE TABLE tournament_games (
+    id SERIAL PRIMARY KEY,
+    tournament_id INTEGER NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL,
+    board INTEGER NOT NULL,
+    replay INTEGER NOT NULL DEFAULT 0,
+    game_id INTEGER UNIQUE REFERENCES games(id) ON DELETE CASCADE,
+    player_x_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    player_o_id INTEGER REFERENCES players(id) ON DELETE CASCADE,
+    CONSTRAINT unique_tournament_game UNIQUE (tournament_id, round, board, replay)
+);
+
+CREATE INDEX idx_tournament_players_player_id ON tournament_players(player_id);
+CREATE INDEX idx_tournaments_status_created_at ON tournaments(status, created_at DESC);
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE moves
+DROP COLUMN extra;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE moves
+ADD COLUMN extra TEXT NOT NULL DEFAULT '';
\ No newline at end of file
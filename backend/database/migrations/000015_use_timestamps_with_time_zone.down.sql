@@ -0,0 +1,15 @@
+ALTER TABLE games
+ALTER COLUMN created_at TYPE TIMESTAMP,
+ALTER COLUMN updated_at TYPE TIMESTAMP;
+
+ALTER TABLE moves
+ALTER COLUMN timestamp TYPE TIMESTAMP;
+
+ALTER TABLE rating_history
+ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE tournaments
+ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE tournament_players
+ALTER COLUMN joined_at TYPE TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- The existing times are read in the time zone of the session, the one they were written in
+ALTER TABLE games
+ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE moves
+ALTER COLUMN timestamp TYPE TIMESTAMPTZ;
+
+ALTER TABLE rating_history
+ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE tournaments
+ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE tournament_players
+ALTER COLUMN joined_at TYPE TIMESTAMPTZ;
\ No newline at end of file
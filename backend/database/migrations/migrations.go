@@ -0,0 +1,238 @@
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Arbitrary key of the advisory lock taken while applying a migration so concurrent servers don't apply it twice
+const lockKey = 7_283_001
+
+var ErrSchemaAhead = errors.New("database schema is ahead of the binary")
+
+// Files are named VERSION_NAME.up.sql and VERSION_NAME.down.sql
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// List returns the embedded migrations ordered by version
+func List() ([]Migration, error) {
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if !fileNameRegexp.MatchString(entry.Name()) {
+			continue
+		}
+		parts := fileNameRegexp.FindStringSubmatch(entry.Name())
+		version, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", entry.Name(), err)
+		}
+		content, err := files.ReadFile(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, parts[2])
+		}
+		if parts[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	var migrations []Migration
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Version returns the highest applied version, 0 if none
+func Version(db *sql.DB) (int64, error) {
+	if err := createVersionTable(db); err != nil {
+		return 0, err
+	}
+	var version int64
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+// Up applies the pending migrations in order, each in its own transaction.
+// It returns ErrSchemaAhead without applying anything if the database has a version unknown to the binary
+func Up(db *sql.DB) error {
+	migrations, err := List()
+	if err != nil {
+		return err
+	}
+	if err = checkNotAhead(db, migrations); err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		err := inTransaction(db, func(tx *sql.Tx) error {
+			applied, err := isApplied(tx, migration.Version)
+			if err != nil || applied {
+				return err
+			}
+			if _, err = tx.Exec(migration.Up); err != nil {
+				return err
+			}
+			_, err = tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", migration.Version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// Down reverts the last steps applied migrations, in reverse order
+func Down(db *sql.DB, steps int) error {
+	migrations, err := List()
+	if err != nil {
+		return err
+	}
+	if err = checkNotAhead(db, migrations); err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		migration := migrations[i]
+		reverted := false
+		err := inTransaction(db, func(tx *sql.Tx) error {
+			applied, err := isApplied(tx, migration.Version)
+			if err != nil || !applied {
+				return err
+			}
+			if migration.Down == "" {
+				return errors.New("no down migration")
+			}
+			if _, err = tx.Exec(migration.Down); err != nil {
+				return err
+			}
+			_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+			reverted = err == nil
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if reverted {
+			steps--
+		}
+	}
+	return nil
+}
+
+// Force marks every migration up to version as applied without running them,
+// for databases created before migrations were tracked
+func Force(db *sql.DB, version int64) error {
+	migrations, err := List()
+	if err != nil {
+		return err
+	}
+	if err = createVersionTable(db); err != nil {
+		return err
+	}
+
+	return inTransaction(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM schema_migrations"); err != nil {
+			return err
+		}
+		for _, migration := range migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", migration.Version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func checkNotAhead(db *sql.DB, migrations []Migration) error {
+	version, err := Version(db)
+	if err != nil {
+		return err
+	}
+	var latest int64
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+	if version > latest {
+		return fmt.Errorf("%w: database is at version %d, binary knows up to %d", ErrSchemaAhead, version, latest)
+	}
+	return nil
+}
+
+func createVersionTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+func isApplied(tx *sql.Tx, version int64) (bool, error) {
+	var applied bool
+	err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)", version).Scan(&applied)
+	return applied, err
+}
+
+// inTransaction runs fn holding the migrations advisory lock, which is released at the end of the transaction
+func inTransaction(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("SELECT pg_advisory_xact_lock($1)", lockKey); err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -8,93 +8,120 @@ import (
 	"github.com/allanlepinay/TicTacToe/backend/types"
 )
 
-func GetBoard(db *sql.DB, move types.Move) [3][3]string {
-	var moves []types.Move
-	res, err := db.Query("SELECT x, y, player FROM moves WHERE game_id = $1", move.GameId)
+// loadGameState replays the moves of the game through its variant to rebuild the board
+func loadGameState(db DBTX, game types.Game) (types.Game, error) {
+	variant, err := gamerules.GetSizedVariant(game.Variant, game.BoardSize)
 	if err != nil {
-		fmt.Println(err)
-		return [3][3]string{}
+		return types.Game{}, err
 	}
+
+	res, err := db.Query("SELECT x, y, extra FROM moves WHERE game_id = $1 ORDER BY id", game.ID)
+	if err != nil {
+		return types.Game{}, err
+	}
+	defer res.Close()
+
+	state := variant.InitialState()
 	for res.Next() {
-		var move types.Move
-		err = res.Scan(&move.X, &move.Y, &move.Turn)
+		var move types.Placement
+		err = res.Scan(&move.X, &move.Y, &move.Extra)
 		if err != nil {
-			fmt.Println(err)
-			return [3][3]string{}
+			return types.Game{}, err
 		}
-		moves = append(moves, move)
+		if !gamerules.IsLegal(variant, state, move) {
+			return types.Game{}, fmt.Errorf("move %d,%d of game %d is illegal", move.X, move.Y, game.ID)
+		}
+		state = variant.ApplyMove(state, move)
 	}
-
-	var board = [3][3]string{}
-	for _, move := range moves {
-		board[move.X][move.Y] = move.Turn
+	if err = res.Err(); err != nil {
+		return types.Game{}, err
 	}
 
-	return board
+	// The row is the reference for everything but the board
+	state.ID = game.ID
+	state.Variant = game.Variant
+	state.Turn = game.Turn
+	state.Status = game.Status
+	state.Result = game.Result
+	state.PlayerXId = game.PlayerXId
+	state.PlayerOId = game.PlayerOId
+	state.Rated = game.Rated
+	if game.Status == types.StatusTerminated {
+		state.WinningLine = variant.Outcome(state).WinningLine
+	}
+	return state, nil
 }
 
-func MakeMove(db *sql.DB, move types.Move) types.Game {
-	player, _ := GetPlayerByName(db, move.Username)
-	board := GetBoard(db, move)
-
-	gameDb, err := GetGame(db, int64(move.GameId))
+func (s *PostgresStore) GetMoves(gameId int64) ([]types.PlayedMove, error) {
+	res, err := s.db.Query("SELECT player, x, y, extra, timestamp FROM moves WHERE game_id = $1 ORDER BY id", gameId)
 	if err != nil {
-		fmt.Println("move.go-MakeMove-GetGame-", err)
-		return types.Game{}
+		return nil, fmt.Errorf("failed to get moves: %w", err)
 	}
+	defer res.Close()
 
-	if (gameDb.Turn == "X" && gameDb.PlayerXId != player.ID) || (gameDb.Turn == "O" && gameDb.PlayerOId != player.ID) {
-		return types.Game{
-			ID:     int64(move.GameId),
-			Board:  board,
-			Turn:   gameDb.Turn,
-			Status: types.StatusInProgress,
+	var moves []types.PlayedMove
+	for res.Next() {
+		move := types.PlayedMove{Ply: int64(len(moves) + 1)}
+		err = res.Scan(&move.Player, &move.X, &move.Y, &move.Extra, &move.Timestamp)
+		if err != nil {
+			return nil, err
 		}
-
+		moves = append(moves, move)
 	}
+	return moves, res.Err()
+}
 
-	_, err = db.Exec("INSERT INTO moves (game_id, player, x, y) VALUES ($1, $2, $3, $4)", move.GameId, gameDb.Turn, move.X, move.Y)
+func (s *PostgresStore) MakeMove(move types.Move) (types.Game, error) {
+	player, err := s.GetPlayerByName(move.Username)
 	if err != nil {
-		fmt.Println("move.go-MakeMove-INSERT", err)
-		return types.Game{
-			ID:     int64(move.GameId),
-			Board:  board,
-			Turn:   gameDb.Turn,
-			Status: types.StatusInProgress,
-		}
+		return types.Game{}, fmt.Errorf("failed to get player: %w", err)
 	}
 
-	board = GetBoard(db, move)
-	victory, _ := gamerules.CheckVictory(board)
-
 	var game types.Game
-	if victory {
-		game = types.Game{
-			ID:     int64(move.GameId),
-			Board:  board,
-			Turn:   gameDb.Turn,
-			Status: types.StatusTerminated,
+	err = s.inTransaction(func(tx *sql.Tx) error {
+		// Lock the game row until commit so concurrent moves on the same game are applied one after the other,
+		// the second one is then validated against the updated turn and board
+		gameDb, err := getGameForUpdate(tx, move.GameId)
+		if err != nil {
+			return fmt.Errorf("failed to get game: %w", err)
+		}
+
+		var outcome types.Outcome
+		game, outcome, err = gamerules.Play(gameDb, player.ID, move.Placement)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("INSERT INTO moves (game_id, player, x, y, extra) VALUES ($1, $2, $3, $4, $5)",
+			move.GameId, gameDb.Turn, move.X, move.Y, move.Extra)
+		if err != nil {
+			return fmt.Errorf("failed to insert move: %w", err)
 		}
-		UpdateGameStatus(db, int64(move.GameId), types.StatusTerminated)
-	} else {
-		var turn string
 
-		if gameDb.Turn == "X" {
-			turn = "O"
-		} else {
-			turn = "X"
+		if outcome.Result != types.ResultOngoing {
+			if err = updateGameResult(tx, move.GameId, outcome.Result); err != nil {
+				return err
+			}
+			if err = updateGameStatus(tx, move.GameId, types.StatusTerminated); err != nil {
+				return err
+			}
+			if gameDb.Rated {
+				if err = updateRatings(tx, gameDb, outcome.Result); err != nil {
+					return err
+				}
+			}
+			return updatePlayersStats(tx, gameDb, outcome.Result)
 		}
 
-		game = types.Game{
-			ID:     int64(move.GameId),
-			Board:  board,
-			Turn:   turn,
-			Status: types.StatusInProgress,
+		if err = updateGameTurn(tx, move.GameId, game.Turn); err != nil {
+			return err
 		}
-		UpdateGameTurn(db, int64(move.GameId))
 		// TODO don't really want to update everytime
-		UpdateGameStatus(db, int64(move.GameId), types.StatusInProgress)
+		return updateGameStatus(tx, move.GameId, types.StatusInProgress)
+	})
+	if err != nil {
+		return types.Game{}, err
 	}
 
-	return game
+	return game, nil
 }
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// Many games so that a missing lock shows up on several cores even without the race detector
+func TestMemoryStoreConcurrentMoves(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 100; i++ {
+		testConcurrentMoves(t, store, gamerules.DefaultVariant)
+	}
+}
+
+func TestPostgresStoreConcurrentMoves(t *testing.T) {
+	testConcurrentMoves(t, openTestPostgresStore(t), gamerules.DefaultVariant)
+}
+
+// openTestPostgresStore opens the database of TEST_DATABASE_CONN_STRING, which is migrated and gets new players,
+// and skips the test if it isn't set
+func openTestPostgresStore(t *testing.T) *PostgresStore {
+	t.Helper()
+	connStr := os.Getenv("TEST_DATABASE_CONN_STRING")
+	if connStr == "" {
+		t.Skip("TEST_DATABASE_CONN_STRING is not set")
+	}
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store := NewPostgresStore(db)
+	if err = store.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// testConcurrentMoves plays a game where every empty cell is played at once by the player whose turn it is:
+// exactly one move must be accepted each turn and the marks must alternate
+func testConcurrentMoves(t *testing.T, store Store, variant string) {
+	suffix := time.Now().UnixNano()
+	playerX, playerO := fmt.Sprintf("x%d", suffix), fmt.Sprintf("o%d", suffix)
+	for _, name := range []string{playerX, playerO} {
+		if err := store.CreatePlayer(name, "hash"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	game, err := store.CreateNewGame(playerX, playerO, variant, types.BoardSize{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for turn := 0; game.Status != types.StatusTerminated; turn++ {
+		player := playerX
+		if turn%2 == 1 {
+			player = playerO
+		}
+
+		var cells []types.Placement
+		for x := range game.Board {
+			for y := range game.Board[x] {
+				if game.Board[x][y] == "" {
+					cells = append(cells, types.Placement{X: x, Y: y})
+				}
+			}
+		}
+
+		start := make(chan struct{})
+		results := make(chan error, len(cells))
+		var wg sync.WaitGroup
+		for _, cell := range cells {
+			wg.Add(1)
+			go func(cell types.Placement) {
+				defer wg.Done()
+				<-start
+				move := types.Move{WebsocketMessage: types.WebsocketMessage{Username: player, GameId: game.ID}, Placement: cell}
+				_, err := store.MakeMove(move)
+				results <- err
+			}(cell)
+		}
+		close(start)
+		wg.Wait()
+		close(results)
+
+		accepted := 0
+		for err := range results {
+			var moveError *gamerules.MoveError
+			switch {
+			case err == nil:
+				accepted++
+			case !errors.As(err, &moveError):
+				t.Fatalf("turn %d: unexpected error: %v", turn, err)
+			}
+		}
+		if accepted != 1 {
+			t.Fatalf("turn %d: %d moves accepted out of %d, want 1", turn, accepted, len(cells))
+		}
+
+		game, err = store.GetGame(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	moves, err := store.GetMoves(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, move := range moves {
+		want := "X"
+		if i%2 == 1 {
+			want = "O"
+		}
+		if move.Player != want {
+			t.Fatalf("move %d played by %s, want %s", i+1, move.Player, want)
+		}
+	}
+}
@@ -1,83 +1,149 @@
 package database
 
 import (
-	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/allanlepinay/TicTacToe/backend/types"
+	"github.com/lib/pq"
 )
 
-func GetPlayerByName(db *sql.DB, username string) (types.Player, error) {
-	var player types.Player
-	err := db.QueryRow("SELECT id, name, websocket_conn FROM players WHERE name = $1", username).Scan(&player.ID, &player.Name, &player.WebsocketConn)
-	if err != nil {
-		return types.Player{}, err
+func (s *PostgresStore) CreatePlayer(name string, passwordHash string) error {
+	_, err := s.db.Exec("INSERT INTO players (name, password_hash) VALUES ($1, $2)", name, passwordHash)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+		return ErrPlayerExists
 	}
-
-	return player, nil
+	return err
 }
 
-func GetPlayersByGameId(db *sql.DB, gameId int64) ([]types.Player, error) {
-	var players []types.Player
-
-	query := `
-		SELECT players.id, players.name
-		FROM players
-		JOIN games ON players.id = games.player_x_id OR players.id = games.player_o_id
-		WHERE games.id = $1
-	`
-	rows, err := db.Query(query, gameId)
+func (s *PostgresStore) GetPasswordHash(name string) (string, error) {
+	var passwordHash string
+	err := s.db.QueryRow("SELECT password_hash FROM players WHERE name = $1 LIMIT 1", name).Scan(&passwordHash)
 	if err != nil {
-		return nil, err
+		return "", notFound(err)
 	}
-	defer rows.Close()
+	return passwordHash, nil
+}
 
-	for rows.Next() {
-		var player types.Player
-		err = rows.Scan(&player.ID, &player.Name, &player.WebsocketConn)
-		if err != nil {
-			return nil, err
-		}
-		players = append(players, player)
-	}
+func (s *PostgresStore) GetPlayerByName(username string) (types.Player, error) {
+	return getPlayerByName(s.db, username)
+}
 
-	return players, nil
+func getPlayerByName(db DBTX, username string) (types.Player, error) {
+	var player types.Player
+	err := db.QueryRow("SELECT id, name, rating, rating_deviation, rating_volatility FROM players WHERE name = $1", username).Scan(&player.ID, &player.Name,
+		&player.Rating.Rating, &player.Rating.Deviation, &player.Rating.Volatility)
+	if err != nil {
+		return types.Player{}, notFound(err)
+	}
 
+	return player, nil
 }
 
-func GetPlayerProfile(db *sql.DB, playerId string) (types.Player, []types.Game, error) {
-	var player types.Player
-	err := db.QueryRow("SELECT id, name FROM players WHERE id = $1", playerId).Scan(&player.ID, &player.Name)
+func (s *PostgresStore) GetPlayerProfile(playerId string) (types.PlayerProfile, error) {
+	var profile types.PlayerProfile
+	err := s.db.QueryRow("SELECT id, name, wins, losses, draws, rating, rating_deviation, rating_volatility FROM players WHERE id = $1", playerId).Scan(&profile.ID, &profile.Name,
+		&profile.Wins, &profile.Loses, &profile.Draw, &profile.Rating.Rating, &profile.Rating.Deviation, &profile.Rating.Volatility)
 	if err != nil {
-		return types.Player{}, nil, err
+		return types.PlayerProfile{}, notFound(err)
+	}
+
+	profile.RatingHistory, err = getRatingHistory(s.db, profile.ID)
+	if err != nil {
+		return types.PlayerProfile{}, err
 	}
 
 	// todo use a function in game.go
-	rows, err := db.Query("SELECT id, status FROM games WHERE player_x_id = $1 OR player_o_id = $1", player.ID)
+	rows, err := s.db.Query("SELECT id, status, result, player_x_id, player_o_id FROM games WHERE player_x_id = $1 OR player_o_id = $1 ORDER BY updated_at DESC", profile.ID)
 	if err != nil {
-		return types.Player{}, nil, err
+		return types.PlayerProfile{}, err
 	}
 	defer rows.Close()
 
-	var games []types.Game
 	for rows.Next() {
 		var game types.Game
-		var statusString string
-		err := rows.Scan(&game.ID, &statusString)
+		var statusString, resultString string
+		err := rows.Scan(&game.ID, &statusString, &resultString, &game.PlayerXId, &game.PlayerOId)
 		if err != nil {
-			return types.Player{}, nil, err
+			return types.PlayerProfile{}, err
 		}
-		for status, name := range types.StatusName {
-			if name == statusString {
-				game.Status = int64(status)
-				break
-			}
+		game.Status, err = getStatusFromName(statusString)
+		if err != nil {
+			return types.PlayerProfile{}, err
 		}
-		if game.Status == 0 && statusString != types.StatusName[types.StatusStarted] {
-			return types.Player{}, nil, fmt.Errorf("unknown game status: %s", statusString)
+		game.Result, err = getResultFromName(resultString)
+		if err != nil {
+			return types.PlayerProfile{}, err
 		}
-		games = append(games, game)
+		profile.Games = append(profile.Games, game)
 	}
 
-	return player, games, nil
+	setProfileStats(&profile)
+
+	return profile, nil
+}
+
+// setProfileStats computes the stats derived from the counters and the games, ordered from the most recent
+func setProfileStats(profile *types.PlayerProfile) {
+	played := profile.Wins + profile.Loses + profile.Draw
+	if played > 0 {
+		profile.WinRate = float64(profile.Wins) / float64(played)
+	}
+	profile.Streak = getCurrentStreak(profile.ID, profile.Games)
+}
+
+// getCurrentStreak expects games ordered from the most recent to the oldest
+func getCurrentStreak(playerId int64, games []types.Game) types.Streak {
+	var streak types.Streak
+	for _, game := range games {
+		if game.Status != types.StatusTerminated {
+			continue
+		}
+		result := getPlayerResult(playerId, game)
+		if streak.Count > 0 && result != streak.Result {
+			break
+		}
+		streak.Result = result
+		streak.Count++
+	}
+	return streak
+}
+
+func getPlayerResult(playerId int64, game types.Game) string {
+	switch {
+	case game.Result == types.ResultDraw:
+		return types.StreakDraw
+	case game.Result == types.ResultXWins && game.PlayerXId == playerId,
+		game.Result == types.ResultOWins && game.PlayerOId == playerId:
+		return types.StreakWin
+	default:
+		return types.StreakLoss
+	}
+}
+
+// updatePlayersStats updates wins, losses and draws of both players of a terminated game
+func updatePlayersStats(db DBTX, game types.Game, result types.GameResult) error {
+	var err error
+	switch result {
+	case types.ResultDraw:
+		_, err = db.Exec("UPDATE players SET draws = draws + 1 WHERE id IN ($1, $2)", game.PlayerXId, game.PlayerOId)
+	case types.ResultXWins:
+		err = updateWinnerAndLoser(db, game.PlayerXId, game.PlayerOId)
+	case types.ResultOWins:
+		err = updateWinnerAndLoser(db, game.PlayerOId, game.PlayerXId)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update players stats: %w", err)
+	}
+	return nil
+}
+
+func updateWinnerAndLoser(db DBTX, winnerId int64, loserId int64) error {
+	_, err := db.Exec("UPDATE players SET wins = wins + 1 WHERE id = $1", winnerId)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("UPDATE players SET losses = losses + 1 WHERE id = $1", loserId)
+	return err
 }
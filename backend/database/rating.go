@@ -0,0 +1,71 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/allanlepinay/TicTacToe/backend/ratings"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// updateRatings applies the result of a rated game to the ratings of its players and records them in their history
+func updateRatings(db DBTX, game types.Game, result types.GameResult) error {
+	// Both rows are locked in id order so two games ending at once between the same players can't deadlock
+	rows, err := db.Query("SELECT id, rating, rating_deviation, rating_volatility FROM players WHERE id IN ($1, $2) ORDER BY id FOR UPDATE",
+		game.PlayerXId, game.PlayerOId)
+	if err != nil {
+		return fmt.Errorf("failed to get ratings: %w", err)
+	}
+	current := make(map[int64]types.Rating)
+	for rows.Next() {
+		var playerId int64
+		var rating types.Rating
+		if err = rows.Scan(&playerId, &rating.Rating, &rating.Deviation, &rating.Volatility); err != nil {
+			rows.Close()
+			return err
+		}
+		current[playerId] = rating
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	newX, newO := ratings.UpdateGame(current[game.PlayerXId], current[game.PlayerOId], result)
+	if err = setRating(db, game.PlayerXId, game.ID, newX); err != nil {
+		return err
+	}
+	return setRating(db, game.PlayerOId, game.ID, newO)
+}
+
+func setRating(db DBTX, playerId int64, gameId int64, rating types.Rating) error {
+	_, err := db.Exec("UPDATE players SET rating = $1, rating_deviation = $2, rating_volatility = $3 WHERE id = $4",
+		rating.Rating, rating.Deviation, rating.Volatility, playerId)
+	if err != nil {
+		return fmt.Errorf("failed to update rating: %w", err)
+	}
+	_, err = db.Exec("INSERT INTO rating_history (player_id, game_id, rating, rating_deviation, rating_volatility) VALUES ($1, $2, $3, $4, $5)",
+		playerId, gameId, rating.Rating, rating.Deviation, rating.Volatility)
+	if err != nil {
+		return fmt.Errorf("failed to insert rating history: %w", err)
+	}
+	return nil
+}
+
+func getRatingHistory(db DBTX, playerId int64) ([]types.RatingChange, error) {
+	rows, err := db.Query("SELECT COALESCE(game_id, 0), rating, rating_deviation, rating_volatility, created_at FROM rating_history WHERE player_id = $1 ORDER BY id", playerId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []types.RatingChange
+	for rows.Next() {
+		var change types.RatingChange
+		err = rows.Scan(&change.GameId, &change.Rating.Rating, &change.Deviation, &change.Volatility, &change.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
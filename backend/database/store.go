@@ -0,0 +1,77 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrPlayerExists = errors.New("player already exists")
+	// ErrInvitationClosed is returned when answering an invitation already accepted, declined or expired
+	ErrInvitationClosed = errors.New("invitation is no longer pending")
+	ErrNotInvited       = errors.New("not invited")
+	// ErrRegistrationClosed is returned when joining or leaving a tournament already started
+	ErrRegistrationClosed  = errors.New("tournament registration is closed")
+	ErrAlreadyRegistered   = errors.New("already registered to the tournament")
+	ErrNotRegistered       = errors.New("not registered to the tournament")
+	ErrTournamentNotActive = errors.New("tournament is not in progress")
+)
+
+// Store gives access to players, games and moves. Sessions are stateless JWT so nothing is stored for them
+type Store interface {
+	CreatePlayer(name string, passwordHash string) error
+	GetPasswordHash(name string) (string, error)
+	GetPlayerByName(name string) (types.Player, error)
+	GetPlayerProfile(playerId string) (types.PlayerProfile, error)
+
+	// CreateNewGame starts a game of the variant, the default one if empty, on a board of the size, the one of the variant if zero
+	CreateNewGame(playerXName string, playerOName string, variant string, size types.BoardSize, rated bool) (types.Game, error)
+	// GetGame returns the game with its current board
+	GetGame(gameId int64) (types.Game, error)
+	GetGameDetails(gameId int64) (types.GameDetails, error)
+	// ListGames returns the games matching the filter, without their board, most recent first, and how many match in total
+	ListGames(filter types.GameFilter) ([]types.GameDetails, int64, error)
+	// GetMoves returns the moves of the game in the order they were played
+	GetMoves(gameId int64) ([]types.PlayedMove, error)
+	// GetLeaderboard returns a page of the standings computed from the rated games matching the filter, with the
+	// standing of the filter player and the number of ranked players. The period, variant and page fields are left empty
+	GetLeaderboard(filter types.LeaderboardFilter) (types.Leaderboard, error)
+
+	// CreateInvitation stores a pending invitation of fromName, a challenge if toName is set, with a new code.
+	// The game is played on a board of the size, the one of the variant if zero
+	CreateInvitation(fromName string, toName string, variant string, size types.BoardSize, rated bool, expiresAt time.Time) (types.Invitation, error)
+	GetInvitation(code string) (types.Invitation, error)
+	// AcceptInvitation creates the game of the pending invitation, username playing O
+	AcceptInvitation(code string, username string) (types.Invitation, types.Game, error)
+	DeclineInvitation(code string, username string) (types.Invitation, error)
+	// GetPendingChallenges returns the challenges waiting for an answer of username
+	GetPendingChallenges(username string) ([]types.Invitation, error)
+	// ExpireInvitations marks the pending invitations expired at now and returns them
+	ExpireInvitations(now time.Time) ([]types.Invitation, error)
+
+	// CreateTournament opens the registration of a tournament of the variant, the default one if empty, played on a board
+	// of the size, the one of the variant if zero.
+	// The format is checked by the tournaments package
+	CreateTournament(name string, creatorName string, format string, variant string, size types.BoardSize, rated bool) (types.Tournament, error)
+	GetTournament(tournamentId int64) (types.Tournament, error)
+	// ListTournaments returns the tournaments, most recent first
+	ListTournaments() ([]types.Tournament, error)
+	JoinTournament(tournamentId int64, username string) (types.Tournament, error)
+	LeaveTournament(tournamentId int64, username string) (types.Tournament, error)
+	// StartTournament closes the registration and stores the players in the order of the seeds
+	StartTournament(tournamentId int64, seeds []string) (types.Tournament, error)
+	// CreateTournamentGames creates the games of the pairings, except the byes, and moves the tournament to their round
+	CreateTournamentGames(tournamentId int64, pairings []types.TournamentPairing) ([]types.TournamentGame, error)
+	// GetTournamentGames returns the games of the tournament by round, board and replay
+	GetTournamentGames(tournamentId int64) ([]types.TournamentGame, error)
+	// GetTournamentOfGame returns the id of the tournament of the game, ErrNotFound if it isn't a tournament game
+	GetTournamentOfGame(gameId int64) (int64, error)
+	FinishTournament(tournamentId int64) error
+
+	// MakeMove validates and applies the move, rule violations are returned as *gamerules.MoveError.
+	// The ratings of the players are updated with the same transaction when it ends a rated game
+	MakeMove(move types.Move) (types.Game, error)
+}
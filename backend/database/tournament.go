@@ -0,0 +1,304 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+	"github.com/lib/pq"
+)
+
+// Players are ordered by seed once the tournament started, NULL seeds being last, and by registration before
+const tournamentQuery = `
+	SELECT tournaments.id, tournaments.name, tournaments.format, tournaments.variant,
+		tournaments.width, tournaments.height, tournaments.win_length, tournaments.rated, tournaments.status,
+		creator.name, tournaments.round, tournaments.created_at,
+		ARRAY(
+			SELECT players.name FROM tournament_players
+			JOIN players ON players.id = tournament_players.player_id
+			WHERE tournament_players.tournament_id = tournaments.id
+			ORDER BY tournament_players.seed, tournament_players.joined_at, players.id
+		)
+	FROM tournaments
+	JOIN players creator ON creator.id = tournaments.creator_id
+`
+
+func (s *PostgresStore) CreateTournament(name string, creatorName string, format string, variant string, size types.BoardSize, rated bool) (types.Tournament, error) {
+	if variant == "" {
+		variant = gamerules.DefaultVariant
+	}
+	size, err := gamerules.BoardSizeOf(variant, size)
+	if err != nil {
+		return types.Tournament{}, err
+	}
+
+	creator, err := s.GetPlayerByName(creatorName)
+	if err != nil {
+		return types.Tournament{}, fmt.Errorf("failed to get tournament creator: %w", err)
+	}
+
+	var tournamentId int64
+	err = s.db.QueryRow(`INSERT INTO tournaments (name, format, variant, width, height, win_length, rated, status, creator_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		name, format, variant, size.Width, size.Height, size.WinLength, rated, types.TournamentStatusName[types.TournamentRegistration], creator.ID).Scan(&tournamentId)
+	if err != nil {
+		return types.Tournament{}, fmt.Errorf("failed to create tournament: %w", err)
+	}
+	return s.GetTournament(tournamentId)
+}
+
+func (s *PostgresStore) GetTournament(tournamentId int64) (types.Tournament, error) {
+	return getTournament(s.db, tournamentQuery+" WHERE tournaments.id = $1", tournamentId)
+}
+
+func getTournament(db DBTX, query string, tournamentId int64) (types.Tournament, error) {
+	tournament, err := scanTournament(db.QueryRow(query, tournamentId))
+	if err != nil {
+		return types.Tournament{}, notFound(err)
+	}
+	return tournament, nil
+}
+
+func scanTournament(row scanner) (types.Tournament, error) {
+	var tournament types.Tournament
+	var statusString string
+	err := row.Scan(&tournament.ID, &tournament.Name, &tournament.Format, &tournament.Variant,
+		&tournament.Width, &tournament.Height, &tournament.WinLength, &tournament.Rated, &statusString,
+		&tournament.Creator, &tournament.Round, &tournament.CreatedAt, pq.Array(&tournament.Players))
+	if err != nil {
+		return types.Tournament{}, err
+	}
+	tournament.Status, err = getTournamentStatusFromName(statusString)
+	if err != nil {
+		return types.Tournament{}, err
+	}
+	return tournament, nil
+}
+
+func getTournamentStatusFromName(statusString string) (int64, error) {
+	for status, name := range types.TournamentStatusName {
+		if name == statusString {
+			return int64(status), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown tournament status: %s", statusString)
+}
+
+func (s *PostgresStore) ListTournaments() ([]types.Tournament, error) {
+	rows, err := s.db.Query(tournamentQuery + " ORDER BY tournaments.created_at DESC, tournaments.id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tournaments: %w", err)
+	}
+	defer rows.Close()
+
+	var tournaments []types.Tournament
+	for rows.Next() {
+		tournament, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, tournament)
+	}
+	return tournaments, rows.Err()
+}
+
+// lockTournament locks the tournament until the end of the transaction and returns it
+func lockTournament(tx *sql.Tx, tournamentId int64) (types.Tournament, error) {
+	return getTournament(tx, tournamentQuery+" WHERE tournaments.id = $1 FOR UPDATE OF tournaments", tournamentId)
+}
+
+func (s *PostgresStore) JoinTournament(tournamentId int64, username string) (types.Tournament, error) {
+	err := s.inTransaction(func(tx *sql.Tx) error {
+		tournament, err := lockTournament(tx, tournamentId)
+		if err != nil {
+			return err
+		}
+		if tournament.Status != types.TournamentRegistration {
+			return ErrRegistrationClosed
+		}
+
+		player, err := getPlayerByName(tx, username)
+		if err != nil {
+			return fmt.Errorf("failed to get player: %w", err)
+		}
+		_, err = tx.Exec("INSERT INTO tournament_players (tournament_id, player_id) VALUES ($1, $2)", tournamentId, player.ID)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return ErrAlreadyRegistered
+		}
+		if err != nil {
+			return fmt.Errorf("failed to join tournament: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return types.Tournament{}, err
+	}
+	return s.GetTournament(tournamentId)
+}
+
+func (s *PostgresStore) LeaveTournament(tournamentId int64, username string) (types.Tournament, error) {
+	err := s.inTransaction(func(tx *sql.Tx) error {
+		tournament, err := lockTournament(tx, tournamentId)
+		if err != nil {
+			return err
+		}
+		if tournament.Status != types.TournamentRegistration {
+			return ErrRegistrationClosed
+		}
+
+		result, err := tx.Exec(`
+			DELETE FROM tournament_players
+			WHERE tournament_id = $1 AND player_id = (SELECT id FROM players WHERE name = $2)
+		`, tournamentId, username)
+		if err != nil {
+			return fmt.Errorf("failed to leave tournament: %w", err)
+		}
+		if deleted, err := result.RowsAffected(); err != nil || deleted == 0 {
+			return ErrNotRegistered
+		}
+		return nil
+	})
+	if err != nil {
+		return types.Tournament{}, err
+	}
+	return s.GetTournament(tournamentId)
+}
+
+func (s *PostgresStore) StartTournament(tournamentId int64, seeds []string) (types.Tournament, error) {
+	err := s.inTransaction(func(tx *sql.Tx) error {
+		tournament, err := lockTournament(tx, tournamentId)
+		if err != nil {
+			return err
+		}
+		if tournament.Status != types.TournamentRegistration {
+			return ErrRegistrationClosed
+		}
+
+		for i, name := range seeds {
+			_, err = tx.Exec(`
+				UPDATE tournament_players SET seed = $1
+				WHERE tournament_id = $2 AND player_id = (SELECT id FROM players WHERE name = $3)
+			`, i+1, tournamentId, name)
+			if err != nil {
+				return fmt.Errorf("failed to seed tournament player: %w", err)
+			}
+		}
+		_, err = tx.Exec("UPDATE tournaments SET status = $1 WHERE id = $2", types.TournamentStatusName[types.TournamentInProgress], tournamentId)
+		if err != nil {
+			return fmt.Errorf("failed to start tournament: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return types.Tournament{}, err
+	}
+	return s.GetTournament(tournamentId)
+}
+
+func (s *PostgresStore) CreateTournamentGames(tournamentId int64, pairings []types.TournamentPairing) ([]types.TournamentGame, error) {
+	var games []types.TournamentGame
+	err := s.inTransaction(func(tx *sql.Tx) error {
+		tournament, err := lockTournament(tx, tournamentId)
+		if err != nil {
+			return err
+		}
+		if tournament.Status != types.TournamentInProgress {
+			return ErrTournamentNotActive
+		}
+
+		round := tournament.Round
+		for _, pairing := range pairings {
+			game := types.TournamentGame{TournamentPairing: pairing, TournamentId: tournamentId}
+			var gameId sql.NullInt64
+			if pairing.PlayerO != "" {
+				created, err := createGame(tx, pairing.PlayerX, pairing.PlayerO, tournament.Variant, tournament.BoardSize, tournament.Rated)
+				if err != nil {
+					return err
+				}
+				game.GameId = created.ID
+				game.Status = created.Status
+				game.Result = created.Result
+				gameId = sql.NullInt64{Int64: created.ID, Valid: true}
+			}
+
+			// The unique constraint on the round, board and replay prevents creating a pairing twice
+			_, err = tx.Exec(`
+				INSERT INTO tournament_games (tournament_id, round, board, replay, game_id, player_x_id, player_o_id)
+				VALUES ($1, $2, $3, $4, $5, (SELECT id FROM players WHERE name = $6), (SELECT id FROM players WHERE name = $7))
+			`, tournamentId, pairing.Round, pairing.Board, pairing.Replay, gameId, pairing.PlayerX, pairing.PlayerO)
+			if err != nil {
+				return fmt.Errorf("failed to create tournament game: %w", err)
+			}
+			games = append(games, game)
+			round = max(round, pairing.Round)
+		}
+
+		_, err = tx.Exec("UPDATE tournaments SET round = $1 WHERE id = $2", round, tournamentId)
+		if err != nil {
+			return fmt.Errorf("failed to update tournament round: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+func (s *PostgresStore) GetTournamentGames(tournamentId int64) ([]types.TournamentGame, error) {
+	rows, err := s.db.Query(`
+		SELECT tournament_games.round, tournament_games.board, tournament_games.replay, COALESCE(tournament_games.game_id, 0),
+			player_x.name, COALESCE(player_o.name, ''), COALESCE(games.status, ''), COALESCE(games.result, '')
+		FROM tournament_games
+		JOIN players player_x ON player_x.id = tournament_games.player_x_id
+		LEFT JOIN players player_o ON player_o.id = tournament_games.player_o_id
+		LEFT JOIN games ON games.id = tournament_games.game_id
+		WHERE tournament_games.tournament_id = $1
+		ORDER BY tournament_games.round, tournament_games.board, tournament_games.replay
+	`, tournamentId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tournament games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []types.TournamentGame
+	for rows.Next() {
+		game := types.TournamentGame{TournamentId: tournamentId}
+		var statusString, resultString string
+		err = rows.Scan(&game.Round, &game.Board, &game.Replay, &game.GameId, &game.PlayerX, &game.PlayerO, &statusString, &resultString)
+		if err != nil {
+			return nil, err
+		}
+		// Byes have no game
+		if game.GameId != 0 {
+			if game.Status, err = getStatusFromName(statusString); err != nil {
+				return nil, err
+			}
+			if game.Result, err = getResultFromName(resultString); err != nil {
+				return nil, err
+			}
+		}
+		games = append(games, game)
+	}
+	return games, rows.Err()
+}
+
+func (s *PostgresStore) GetTournamentOfGame(gameId int64) (int64, error) {
+	var tournamentId int64
+	err := s.db.QueryRow("SELECT tournament_id FROM tournament_games WHERE game_id = $1", gameId).Scan(&tournamentId)
+	if err != nil {
+		return 0, notFound(err)
+	}
+	return tournamentId, nil
+}
+
+func (s *PostgresStore) FinishTournament(tournamentId int64) error {
+	_, err := s.db.Exec("UPDATE tournaments SET status = $1 WHERE id = $2", types.TournamentStatusName[types.TournamentFinished], tournamentId)
+	if err != nil {
+		return fmt.Errorf("failed to finish tournament: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package gamerules
+
+import (
+	"fmt"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// Largest board accepted, big enough for Gomoku
+const MaxBoardSize = 19
+
+var (
+	Classic = types.BoardSize{Width: 3, Height: 3, WinLength: 3}
+	Gomoku  = types.BoardSize{Width: 15, Height: 15, WinLength: 5}
+)
+
+func init() {
+	Register("classic", MNK{Size: Classic})
+	Register("gomoku", MNK{Size: Gomoku})
+}
+
+// MNK is a m,n,k-game: players alternate on a Width x Height board and the first with WinLength marks in a row wins
+type MNK struct {
+	Size types.BoardSize
+}
+
+// ValidateBoardSize checks the size of a m,n,k-game
+func ValidateBoardSize(size types.BoardSize) error {
+	if size.Width < 1 || size.Width > MaxBoardSize || size.Height < 1 || size.Height > MaxBoardSize {
+		return fmt.Errorf("board must be between 1x1 and %dx%d", MaxBoardSize, MaxBoardSize)
+	}
+	if size.WinLength < 1 || (size.WinLength > size.Width && size.WinLength > size.Height) {
+		return fmt.Errorf("win length %d doesn't fit on a %dx%d board", size.WinLength, size.Width, size.Height)
+	}
+	return nil
+}
+
+func (v MNK) Resize(size types.BoardSize) (Variant, error) {
+	if err := ValidateBoardSize(size); err != nil {
+		return nil, err
+	}
+	return MNK{Size: size}, nil
+}
+
+func (v MNK) InitialState() types.Game {
+	return types.Game{
+		Board:     NewBoard(v.Size),
+		Turn:      "X",
+		BoardSize: v.Size,
+	}
+}
+
+func (v MNK) LegalMoves(game types.Game) []types.Placement {
+	var moves []types.Placement
+	for x := range game.Board {
+		for y := range game.Board[x] {
+			if game.Board[x][y] == "" {
+				moves = append(moves, types.Placement{X: x, Y: y})
+			}
+		}
+	}
+	return moves
+}
+
+func (v MNK) ApplyMove(game types.Game, move types.Placement) types.Game {
+	game.Board = CopyBoard(game.Board)
+	game.Board[move.X][move.Y] = game.Turn
+	game.Turn = nextTurn(game.Turn)
+	return game
+}
+
+func (v MNK) Outcome(game types.Game) types.Outcome {
+	return GetOutcome(game.Board, game.WinLength)
+}
@@ -1,25 +1,93 @@
 package gamerules
 
-// TODO checkDraw
-func CheckVictory(board [3][3]string) (bool, string) {
-	// Check rows
-	for _, row := range board {
-		if row[0] != "" && row[0] == row[1] && row[1] == row[2] {
-			return true, row[0]
+import (
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// Directions checked from each cell, the opposite ones are covered by starting from the other end of the line
+var directions = [][2]int{
+	{0, 1},  // Row
+	{1, 0},  // Column
+	{1, 1},  // Diagonal
+	{1, -1}, // Anti-diagonal
+}
+
+// NewBoard returns an empty board, indexed by board[x][y] with x the row
+func NewBoard(size types.BoardSize) [][]string {
+	board := make([][]string, size.Height)
+	for x := range board {
+		board[x] = make([]string, size.Width)
+	}
+	return board
+}
+
+func CopyBoard(board [][]string) [][]string {
+	if board == nil {
+		return nil
+	}
+	copied := make([][]string, len(board))
+	for x := range board {
+		copied[x] = append([]string(nil), board[x]...)
+	}
+	return copied
+}
+
+func InBounds(board [][]string, x int, y int) bool {
+	return x >= 0 && x < len(board) && y >= 0 && y < len(board[x])
+}
+
+// GetOutcome returns the result of the board and the winning line if there is one
+func GetOutcome(board [][]string, winLength int) types.Outcome {
+	for x := range board {
+		for y := range board[x] {
+			// Only X and O can win, other marks (Ultimate draws) block lines
+			if board[x][y] != "X" && board[x][y] != "O" {
+				continue
+			}
+			for _, direction := range directions {
+				line := getLine(board, x, y, direction, winLength)
+				if line == nil {
+					continue
+				}
+				var result types.GameResult = types.ResultXWins
+				if board[x][y] == "O" {
+					result = types.ResultOWins
+				}
+				return types.Outcome{
+					Result:      result,
+					WinningLine: line,
+				}
+			}
 		}
 	}
-	// Check columns
-	for col := 0; col < 3; col++ {
-		if board[0][col] != "" && board[0][col] == board[1][col] && board[1][col] == board[2][col] {
-			return true, board[0][col]
+
+	for _, row := range board {
+		for _, cell := range row {
+			if cell == "" {
+				return types.Outcome{Result: types.ResultOngoing}
+			}
 		}
 	}
-	// Check diagonals
-	if board[0][0] != "" && board[0][0] == board[1][1] && board[1][1] == board[2][2] {
-		return true, board[0][0]
+
+	return types.Outcome{Result: types.ResultDraw}
+}
+
+// getLine returns the winLength cells starting at x, y in the direction if they all hold the same mark
+func getLine(board [][]string, x int, y int, direction [2]int, winLength int) [][2]int {
+	line := make([][2]int, 0, winLength)
+	for i := 0; i < winLength; i++ {
+		cellX, cellY := x+i*direction[0], y+i*direction[1]
+		if !InBounds(board, cellX, cellY) || board[cellX][cellY] != board[x][y] {
+			return nil
+		}
+		line = append(line, [2]int{cellX, cellY})
 	}
-	if board[0][2] != "" && board[0][2] == board[1][1] && board[1][1] == board[2][0] {
-		return true, board[0][2]
+	return line
+}
+
+func nextTurn(turn string) string {
+	if turn == "X" {
+		return "O"
 	}
-	return false, ""
+	return "X"
 }
@@ -0,0 +1,92 @@
+package gamerules
+
+import "github.com/allanlepinay/TicTacToe/backend/types"
+
+// Mark of a sub-board full without winner on the meta-board
+const drawMark = "D"
+
+func init() {
+	Register("ultimate", Ultimate{})
+}
+
+// Ultimate is Ultimate tic-tac-toe: a 3x3 meta-board of classic boards, stored as a 9x9 Board.
+// Playing in a cell sends the opponent to the sub-board at the same position, if that sub-board
+// is won or full they can play in any open sub-board. Winning three sub-boards in a row wins the game
+type Ultimate struct{}
+
+func (v Ultimate) InitialState() types.Game {
+	return types.Game{
+		Board:     NewBoard(types.BoardSize{Width: 9, Height: 9}),
+		Turn:      "X",
+		BoardSize: types.BoardSize{Width: 9, Height: 9, WinLength: 3},
+		MetaBoard: NewBoard(Classic),
+	}
+}
+
+func (v Ultimate) LegalMoves(game types.Game) []types.Placement {
+	var moves []types.Placement
+	for x := range game.Board {
+		for y := range game.Board[x] {
+			subX, subY := x/3, y/3
+			if game.Board[x][y] != "" || game.MetaBoard[subX][subY] != "" {
+				continue
+			}
+			if game.ActiveBoard != nil && (game.ActiveBoard[0] != subX || game.ActiveBoard[1] != subY) {
+				continue
+			}
+			moves = append(moves, types.Placement{X: x, Y: y})
+		}
+	}
+	return moves
+}
+
+func (v Ultimate) ApplyMove(game types.Game, move types.Placement) types.Game {
+	x, y := move.X, move.Y
+	game.Board = CopyBoard(game.Board)
+	game.MetaBoard = CopyBoard(game.MetaBoard)
+	game.Board[x][y] = game.Turn
+
+	subX, subY := x/3, y/3
+	switch GetOutcome(subBoard(game.Board, subX, subY), 3).Result {
+	case types.ResultXWins:
+		game.MetaBoard[subX][subY] = "X"
+	case types.ResultOWins:
+		game.MetaBoard[subX][subY] = "O"
+	case types.ResultDraw:
+		game.MetaBoard[subX][subY] = drawMark
+	}
+
+	// The opponent is sent to the sub-board matching the cell, unless it is already decided
+	target := [2]int{x % 3, y % 3}
+	if game.MetaBoard[target[0]][target[1]] == "" {
+		game.ActiveBoard = &target
+	} else {
+		game.ActiveBoard = nil
+	}
+	game.Turn = nextTurn(game.Turn)
+	return game
+}
+
+// Outcome is the outcome of the meta-board, the winning line covers every cell of the three winning sub-boards
+func (v Ultimate) Outcome(game types.Game) types.Outcome {
+	outcome := GetOutcome(game.MetaBoard, 3)
+
+	var winningLine [][2]int
+	for _, sub := range outcome.WinningLine {
+		for x := 0; x < 3; x++ {
+			for y := 0; y < 3; y++ {
+				winningLine = append(winningLine, [2]int{sub[0]*3 + x, sub[1]*3 + y})
+			}
+		}
+	}
+	outcome.WinningLine = winningLine
+	return outcome
+}
+
+func subBoard(board [][]string, subX int, subY int) [][]string {
+	sub := make([][]string, 3)
+	for x := range sub {
+		sub[x] = board[subX*3+x][subY*3 : subY*3+3]
+	}
+	return sub
+}
@@ -0,0 +1,56 @@
+package gamerules
+
+import "github.com/allanlepinay/TicTacToe/backend/types"
+
+// MoveError is returned when a move is rejected by the rules, its message is safe to send back to the player
+type MoveError struct {
+	Reason string
+}
+
+func (e *MoveError) Error() string {
+	return e.Reason
+}
+
+var (
+	ErrOutOfBounds  = &MoveError{Reason: "Move is outside of the board"}
+	ErrCellOccupied = &MoveError{Reason: "Cell is already occupied"}
+	ErrGameFinished = &MoveError{Reason: "Game is already finished"}
+	ErrNotPlayer    = &MoveError{Reason: "You are not a player of this game"}
+	ErrWrongPlayer  = &MoveError{Reason: "It is not your turn"}
+	ErrIllegalMove  = &MoveError{Reason: "Move is not allowed"}
+)
+
+// ValidateMove checks that the player can play the move on the game, game.Board must be up to date
+func ValidateMove(variant Variant, game types.Game, playerId int64, move types.Placement) error {
+	if game.Status == types.StatusTerminated {
+		return ErrGameFinished
+	}
+	// Spectators included
+	if playerId != game.PlayerXId && playerId != game.PlayerOId {
+		return ErrNotPlayer
+	}
+	if (game.Turn == "X" && game.PlayerXId != playerId) || (game.Turn == "O" && game.PlayerOId != playerId) {
+		return ErrWrongPlayer
+	}
+	if !InBounds(game.Board, move.X, move.Y) {
+		return ErrOutOfBounds
+	}
+	if game.Board[move.X][move.Y] != "" {
+		return ErrCellOccupied
+	}
+	// Rules of the variant, like the sub-board to play in of Ultimate
+	if !IsLegal(variant, game, move) {
+		return ErrIllegalMove
+	}
+	return nil
+}
+
+// IsLegal tells if the move is one of the legal moves of the variant on the game
+func IsLegal(variant Variant, game types.Game, move types.Placement) bool {
+	for _, legal := range variant.LegalMoves(game) {
+		if legal == move {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package gamerules
+
+import (
+	"testing"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+func TestValidateMove(t *testing.T) {
+	classic, _ := NewGame("classic", types.BoardSize{})
+	classic.PlayerXId, classic.PlayerOId = 1, 2
+	classic, _, err := Play(classic, 1, types.Placement{X: 1, Y: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ultimate, _ := NewGame("ultimate", types.BoardSize{})
+	ultimate.PlayerXId, ultimate.PlayerOId = 1, 2
+	// O is sent to the top left sub-board
+	ultimate, _, err = Play(ultimate, 1, types.Placement{X: 3, Y: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		game     types.Game
+		playerId int64
+		move     types.Placement
+		want     error
+	}{
+		{"legal", classic, 2, types.Placement{X: 0, Y: 0}, nil},
+		{"not a player", classic, 3, types.Placement{X: 0, Y: 0}, ErrNotPlayer},
+		{"not their turn", classic, 1, types.Placement{X: 0, Y: 0}, ErrWrongPlayer},
+		{"outside of the board", classic, 2, types.Placement{X: 3, Y: 0}, ErrOutOfBounds},
+		{"negative cell", classic, 2, types.Placement{X: 0, Y: -1}, ErrOutOfBounds},
+		{"occupied cell", classic, 2, types.Placement{X: 1, Y: 1}, ErrCellOccupied},
+		{"unknown payload", classic, 2, types.Placement{X: 0, Y: 0, Extra: "O"}, ErrIllegalMove},
+		{"active sub-board", ultimate, 2, types.Placement{X: 0, Y: 0}, nil},
+		{"other sub-board", ultimate, 2, types.Placement{X: 8, Y: 8}, ErrIllegalMove},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			variant, err := GetVariant(test.game.Variant)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ValidateMove(variant, test.game, test.playerId, test.move); err != test.want {
+				t.Errorf("ValidateMove = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
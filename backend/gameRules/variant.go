@@ -0,0 +1,174 @@
+package gamerules
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+const DefaultVariant = "classic"
+
+// Variant holds the rules of a kind of game, variants register themselves with Register in an init function
+type Variant interface {
+	// InitialState returns a new game with its empty board, size and first turn
+	InitialState() types.Game
+	// LegalMoves returns every move the current player can play, the only ones accepted
+	LegalMoves(game types.Game) []types.Placement
+	// ApplyMove returns the game once the current player played the move and the turn passed, the move must be legal
+	ApplyMove(game types.Game, move types.Placement) types.Game
+	Outcome(game types.Game) types.Outcome
+}
+
+// Resizable is implemented by the variants which can be played on boards of other sizes than their own
+type Resizable interface {
+	// Resize returns the variant played on a board of the size, or an error if the size isn't supported
+	Resize(size types.BoardSize) (Variant, error)
+}
+
+var (
+	variantsMutex sync.RWMutex
+	variants      = make(map[string]Variant)
+)
+
+// Register makes a variant available under name, it panics if the name is already taken
+func Register(name string, variant Variant) {
+	variantsMutex.Lock()
+	defer variantsMutex.Unlock()
+
+	if _, ok := variants[name]; ok {
+		panic("gamerules: variant registered twice: " + name)
+	}
+	variants[name] = variant
+}
+
+// GetVariant returns the variant registered under name, the default one if name is empty
+func GetVariant(name string) (Variant, error) {
+	if name == "" {
+		name = DefaultVariant
+	}
+
+	variantsMutex.RLock()
+	defer variantsMutex.RUnlock()
+
+	variant, ok := variants[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown variant: %s", name)
+	}
+	return variant, nil
+}
+
+// GetSizedVariant returns the variant registered under name played on a board of the size, its own board if size is zero
+func GetSizedVariant(name string, size types.BoardSize) (Variant, error) {
+	variant, err := GetVariant(name)
+	if err != nil {
+		return nil, err
+	}
+	if size == (types.BoardSize{}) || size == variant.InitialState().BoardSize {
+		return variant, nil
+	}
+	resizable, ok := variant.(Resizable)
+	if !ok {
+		return nil, fmt.Errorf("variant %s can't be played on a %dx%d board", name, size.Width, size.Height)
+	}
+	return resizable.Resize(size)
+}
+
+// BoardSizeOf returns the board the variant registered under name is played on with the size, its own if size is zero.
+// It fails for an unknown variant or a size the variant doesn't support
+func BoardSizeOf(name string, size types.BoardSize) (types.BoardSize, error) {
+	if name == "" {
+		name = DefaultVariant
+	}
+	variant, err := GetSizedVariant(name, size)
+	if err != nil {
+		return types.BoardSize{}, err
+	}
+	return variant.InitialState().BoardSize, nil
+}
+
+// Variants returns the names of the registered variants, sorted
+func Variants() []string {
+	variantsMutex.RLock()
+	defer variantsMutex.RUnlock()
+
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewGame returns the initial state of a game of the variant on a board of the size, its own board if size is zero
+func NewGame(variantName string, size types.BoardSize) (types.Game, error) {
+	if variantName == "" {
+		variantName = DefaultVariant
+	}
+	variant, err := GetSizedVariant(variantName, size)
+	if err != nil {
+		return types.Game{}, err
+	}
+	game := variant.InitialState()
+	game.Variant = variantName
+	game.Status = types.StatusStarted
+	return game, nil
+}
+
+// Replay returns the game before the first move and after each of the moves
+func Replay(variantName string, size types.BoardSize, moves []types.PlayedMove) ([]types.Game, error) {
+	game, err := NewGame(variantName, size)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := GetSizedVariant(variantName, size)
+	if err != nil {
+		return nil, err
+	}
+
+	states := []types.Game{game}
+	for _, move := range moves {
+		if !IsLegal(variant, game, move.Placement) {
+			return nil, fmt.Errorf("invalid move %d,%d at ply %d", move.X, move.Y, move.Ply)
+		}
+		turn := game.Turn
+		game = variant.ApplyMove(game, move.Placement)
+		outcome := variant.Outcome(game)
+		if outcome.Result != types.ResultOngoing {
+			// Like Play, the turn stays on the player who ended the game
+			game.Turn = turn
+			game.Status = types.StatusTerminated
+			game.Result = int64(outcome.Result)
+			game.WinningLine = outcome.WinningLine
+		} else {
+			game.Status = types.StatusInProgress
+		}
+		states = append(states, game)
+	}
+	return states, nil
+}
+
+// Play validates and applies the move of playerId, the returned game has its status and result updated
+func Play(game types.Game, playerId int64, move types.Placement) (types.Game, types.Outcome, error) {
+	variant, err := GetSizedVariant(game.Variant, game.BoardSize)
+	if err != nil {
+		return game, types.Outcome{}, err
+	}
+	if err = ValidateMove(variant, game, playerId, move); err != nil {
+		return game, types.Outcome{}, err
+	}
+
+	next := variant.ApplyMove(game, move)
+	outcome := variant.Outcome(next)
+	if outcome.Result != types.ResultOngoing {
+		// The turn stays on the player who ended the game
+		next.Turn = game.Turn
+		next.Status = types.StatusTerminated
+		next.Result = int64(outcome.Result)
+		next.WinningLine = outcome.WinningLine
+	} else {
+		next.Status = types.StatusInProgress
+	}
+	return next, outcome, nil
+}
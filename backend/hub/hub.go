@@ -0,0 +1,253 @@
+package hub
+
+import (
+	"sync"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+	"github.com/gorilla/websocket"
+)
+
+const sendQueueSize = 64
+
+// Conn wraps a websocket connection, every write goes through its send queue
+// so only the writer goroutine ever writes on the websocket
+type Conn struct {
+	ws       *websocket.Conn
+	username string
+	send     chan any
+
+	mutex  sync.Mutex
+	closed bool
+}
+
+func (c *Conn) Username() string {
+	return c.username
+}
+
+// Send queues the message, if the client is too slow to keep up the connection is closed
+func (c *Conn) Send(message any) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- message:
+	default:
+		c.close()
+	}
+}
+
+// close must be called with the mutex held
+func (c *Conn) close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.send)
+	// Unblock the reader, it will unregister the connection
+	c.ws.Close()
+}
+
+func (c *Conn) writePump() {
+	for message := range c.send {
+		if err := c.ws.WriteJSON(message); err != nil {
+			c.ws.Close()
+		}
+	}
+}
+
+// Hub owns all the websocket connections, which game each of them is playing and which games they watch
+type Hub struct {
+	mutex           sync.RWMutex
+	connsByUsername map[string]map[*Conn]bool
+	// Only one connection per player and game, the last one to join wins
+	connsByGame      map[int64]map[string]*Conn
+	spectatorsByGame map[int64]map[*Conn]bool
+	// Connections following the games created and ended on the server
+	lobby map[*Conn]bool
+}
+
+func New() *Hub {
+	return &Hub{
+		connsByUsername:  make(map[string]map[*Conn]bool),
+		connsByGame:      make(map[int64]map[string]*Conn),
+		spectatorsByGame: make(map[int64]map[*Conn]bool),
+		lobby:            make(map[*Conn]bool),
+	}
+}
+
+// Register starts the writer goroutine of the connection, Unregister must be called once the socket is closed
+func (h *Hub) Register(ws *websocket.Conn, username string) *Conn {
+	conn := &Conn{
+		ws:       ws,
+		username: username,
+		send:     make(chan any, sendQueueSize),
+	}
+	go conn.writePump()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.connsByUsername[username] == nil {
+		h.connsByUsername[username] = make(map[*Conn]bool)
+	}
+	h.connsByUsername[username][conn] = true
+
+	return conn
+}
+
+// Unregister removes every reference to the connection and stops its writer goroutine
+func (h *Hub) Unregister(conn *Conn) {
+	h.mutex.Lock()
+	delete(h.connsByUsername[conn.username], conn)
+	if len(h.connsByUsername[conn.username]) == 0 {
+		delete(h.connsByUsername, conn.username)
+	}
+	for gameId, conns := range h.connsByGame {
+		if conns[conn.username] == conn {
+			delete(conns, conn.username)
+		}
+		if len(conns) == 0 {
+			delete(h.connsByGame, gameId)
+		}
+	}
+	delete(h.lobby, conn)
+	var watched []int64
+	for gameId, spectators := range h.spectatorsByGame {
+		if spectators[conn] {
+			watched = append(watched, gameId)
+			h.removeSpectator(conn, gameId)
+		}
+	}
+	h.mutex.Unlock()
+
+	for _, gameId := range watched {
+		h.sendSpectatorCount(gameId)
+	}
+
+	conn.mutex.Lock()
+	conn.close()
+	conn.mutex.Unlock()
+}
+
+// BindGame makes conn the connection receiving the game messages for its player,
+// replacing the previous one (page refresh, new tab)
+func (h *Hub) BindGame(conn *Conn, gameId int64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.connsByGame[gameId] == nil {
+		h.connsByGame[gameId] = make(map[string]*Conn)
+	}
+	h.connsByGame[gameId][conn.username] = conn
+}
+
+// UnbindGame forgets the connections and spectators of a game, typically once it is terminated
+func (h *Hub) UnbindGame(gameId int64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.connsByGame, gameId)
+	delete(h.spectatorsByGame, gameId)
+}
+
+// Spectate makes conn receive the messages of the game, the new spectator count is sent to the game
+func (h *Hub) Spectate(conn *Conn, gameId int64) {
+	h.mutex.Lock()
+	if h.spectatorsByGame[gameId] == nil {
+		h.spectatorsByGame[gameId] = make(map[*Conn]bool)
+	}
+	h.spectatorsByGame[gameId][conn] = true
+	h.mutex.Unlock()
+
+	h.sendSpectatorCount(gameId)
+}
+
+// StopSpectating stops sending the messages of the game to conn, the new spectator count is sent to the game
+func (h *Hub) StopSpectating(conn *Conn, gameId int64) {
+	h.mutex.Lock()
+	if !h.spectatorsByGame[gameId][conn] {
+		h.mutex.Unlock()
+		return
+	}
+	h.removeSpectator(conn, gameId)
+	h.mutex.Unlock()
+
+	h.sendSpectatorCount(gameId)
+}
+
+// removeSpectator must be called with the mutex held
+func (h *Hub) removeSpectator(conn *Conn, gameId int64) {
+	delete(h.spectatorsByGame[gameId], conn)
+	if len(h.spectatorsByGame[gameId]) == 0 {
+		delete(h.spectatorsByGame, gameId)
+	}
+}
+
+// IsSpectating tells if conn watches the game
+func (h *Hub) IsSpectating(conn *Conn, gameId int64) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return h.spectatorsByGame[gameId][conn]
+}
+
+func (h *Hub) SpectatorCount(gameId int64) int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return len(h.spectatorsByGame[gameId])
+}
+
+func (h *Hub) sendSpectatorCount(gameId int64) {
+	h.SendToGame(gameId, types.SpectatorCount{Type: "spectators", GameId: gameId, Count: h.SpectatorCount(gameId)})
+}
+
+func (h *Hub) SubscribeLobby(conn *Conn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.lobby[conn] = true
+}
+
+func (h *Hub) UnsubscribeLobby(conn *Conn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.lobby, conn)
+}
+
+// SendToLobby sends the message to the connections subscribed to the lobby
+func (h *Hub) SendToLobby(message any) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for conn := range h.lobby {
+		conn.Send(message)
+	}
+}
+
+// SendToPlayer sends the message to every connection of the player
+func (h *Hub) SendToPlayer(username string, message any) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for conn := range h.connsByUsername[username] {
+		conn.Send(message)
+	}
+}
+
+// SendToGame sends the message to the connections bound to the game and to its spectators
+func (h *Hub) SendToGame(gameId int64, message any) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for _, conn := range h.connsByGame[gameId] {
+		conn.Send(message)
+	}
+	for conn := range h.spectatorsByGame[gameId] {
+		conn.Send(message)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/allanlepinay/TicTacToe/backend/ai"
+	"github.com/allanlepinay/TicTacToe/backend/database"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// createBots makes sure there is a player for every bot level
+func createBots(store database.Store) error {
+	for level := range ai.LevelName {
+		// No password hash, nobody can log in as a bot
+		err := store.CreatePlayer(ai.BotName(level), "")
+		if err != nil && !errors.Is(err, database.ErrPlayerExists) {
+			return fmt.Errorf("failed to create bot %s: %w", ai.BotName(level), err)
+		}
+	}
+	return nil
+}
+
+// playBotTurn plays the move of the bot if it is its turn in the game, through MakeMove like any player
+func playBotTurn(store database.Store, gameId int64) {
+	details, err := store.GetGameDetails(gameId)
+	if err != nil {
+		fmt.Println("Failed to get bot game:", err)
+		return
+	}
+	if details.Status == types.StatusTerminated {
+		return
+	}
+
+	botName := details.PlayerXName
+	if details.Turn == "O" {
+		botName = details.PlayerOName
+	}
+	level, ok := ai.BotLevel(botName)
+	if !ok {
+		return
+	}
+
+	placement, err := ai.ChooseMove(details.Game, level)
+	if err != nil {
+		fmt.Println("Bot failed to choose a move:", err)
+		return
+	}
+
+	move := types.Move{
+		WebsocketMessage: types.WebsocketMessage{
+			Type:     "move",
+			Username: botName,
+			GameId:   gameId,
+		},
+		Placement: placement,
+	}
+	game, err := store.MakeMove(move)
+	if err != nil {
+		fmt.Println("Bot failed to make move:", err)
+		return
+	}
+	broadcastGame(store, game, botName)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/allanlepinay/TicTacToe/backend/database"
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// broadcastGame sends the game state to the connections bound to the game, after a move of username
+func broadcastGame(store database.Store, game types.Game, username string) {
+	gameJSON, _ := json.Marshal(game)
+	wsHub.SendToGame(game.ID, types.WebsocketMessage{
+		Type:     "move",
+		Message:  string(gameJSON),
+		Username: username,
+		GameId:   game.ID,
+	})
+	if game.Status == types.StatusTerminated {
+		wsHub.UnbindGame(game.ID)
+		announceGame(store, game.ID, "lobbyGameEnded")
+		if err := tournamentManager.GameEnded(game.ID); err != nil {
+			fmt.Println("Failed to advance tournament:", err)
+		}
+	}
+}
+
+// announceGame sends the game, without its board, to the connections following the lobby
+func announceGame(store database.Store, gameId int64, eventType string) {
+	game, err := store.GetGameDetails(gameId)
+	if err != nil {
+		fmt.Println("Failed to get game to announce:", err)
+		return
+	}
+	game.Board = nil
+	game.MetaBoard = nil
+	game.ActiveBoard = nil
+	game.WinningLine = nil
+
+	gameJSON, _ := json.Marshal(game)
+	wsHub.SendToLobby(types.WebsocketMessage{
+		Type:    eventType,
+		Message: string(gameJSON),
+		GameId:  gameId,
+	})
+}
+
+// ListGames returns a page of the games matching the status, player, variant, from and to query parameters.
+// Dates are YYYY-MM-DD or RFC 3339, pages start at 1
+func ListGames(store database.Store, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var filter types.GameFilter
+
+	if statusName := query.Get("status"); statusName != "" {
+		status, ok := parseStatus(statusName)
+		if !ok {
+			http.Error(w, "Invalid status", http.StatusBadRequest)
+			return
+		}
+		filter.Status = &status
+	}
+	filter.Player = query.Get("player")
+	filter.Variant = query.Get("variant")
+	if filter.Variant != "" {
+		if _, err := gamerules.GetVariant(filter.Variant); err != nil {
+			http.Error(w, "Unknown variant", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var err error
+	if filter.From, err = parseDate(query.Get("from")); err != nil {
+		http.Error(w, "Invalid from date", http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseDate(query.Get("to")); err != nil {
+		http.Error(w, "Invalid to date", http.StatusBadRequest)
+		return
+	}
+
+	page, pageSize, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	games, total, err := store.ListGames(filter)
+	if err != nil {
+		fmt.Println("Failed to list games:", err)
+		http.Error(w, "Failed to list games", http.StatusInternalServerError)
+		return
+	}
+	if games == nil {
+		games = []types.GameDetails{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.GameList{
+		Games:    games,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// parsePage reads the page and page_size query parameters, otherwise it writes the error response and returns false
+func parsePage(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	page, pageSize := 1, defaultPageSize
+	var err error
+	if value := r.URL.Query().Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			http.Error(w, "Invalid page", http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+	if value := r.URL.Query().Get("page_size"); value != "" {
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			http.Error(w, fmt.Sprintf("Page size must be between 1 and %d", maxPageSize), http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+	return page, pageSize, true
+}
+
+func parseStatus(name string) (int64, bool) {
+	for status, statusName := range types.StatusName {
+		if statusName == name {
+			return int64(status), true
+		}
+	}
+	return 0, false
+}
+
+// parseDate returns the zero time for an empty value
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/allanlepinay/TicTacToe/backend/ai"
+	"github.com/allanlepinay/TicTacToe/backend/database"
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/hub"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+const (
+	// A private game code can be shared for a while, a challenge needs an answer quickly
+	inviteTimeout    = 24 * time.Hour
+	challengeTimeout = 2 * time.Minute
+)
+
+// createPrivateGame answers with the code to share with the opponent, the creator plays X
+func createPrivateGame(store database.Store, conn *hub.Conn, variant string, size types.BoardSize, rated bool) {
+	if _, err := gamerules.GetVariant(variant); err != nil {
+		sendError(conn, -1, "Unknown variant")
+		return
+	}
+	if _, err := gamerules.BoardSizeOf(variant, size); err != nil {
+		sendError(conn, -1, "Invalid board: "+err.Error())
+		return
+	}
+
+	invitation, err := store.CreateInvitation(conn.Username(), "", variant, size, rated, time.Now().Add(inviteTimeout))
+	if err != nil {
+		fmt.Println("Failed to create private game:", err)
+		sendError(conn, -1, "Failed to create private game")
+		return
+	}
+	sendInvitation(conn.Username(), "privateGameCreated", invitation)
+}
+
+// challenge sends a challenge to opponent through their websocket, the challenger plays X
+func challenge(store database.Store, conn *hub.Conn, opponent string, variant string, size types.BoardSize, rated bool) {
+	if _, err := gamerules.GetVariant(variant); err != nil {
+		sendError(conn, -1, "Unknown variant")
+		return
+	}
+	if _, err := gamerules.BoardSizeOf(variant, size); err != nil {
+		sendError(conn, -1, "Invalid board: "+err.Error())
+		return
+	}
+	if _, isBot := ai.BotLevel(opponent); isBot || opponent == conn.Username() {
+		sendError(conn, -1, "You can't challenge this player")
+		return
+	}
+
+	invitation, err := store.CreateInvitation(conn.Username(), opponent, variant, size, rated, time.Now().Add(challengeTimeout))
+	if errors.Is(err, database.ErrNotFound) {
+		sendError(conn, -1, "Player not found")
+		return
+	}
+	if err != nil {
+		fmt.Println("Failed to create challenge:", err)
+		sendError(conn, -1, "Failed to create challenge")
+		return
+	}
+	sendInvitation(conn.Username(), "challengeSent", invitation)
+	sendInvitation(opponent, "challenge", invitation)
+}
+
+// acceptInvitation starts the game of a private game code or a challenge, both players are sent the game
+func acceptInvitation(store database.Store, conn *hub.Conn, code string) {
+	// Codes are typed by hand
+	invitation, game, err := store.AcceptInvitation(strings.ToUpper(strings.TrimSpace(code)), conn.Username())
+	if err != nil {
+		sendInvitationError(conn, err)
+		return
+	}
+
+	announceGame(store, game.ID, "lobbyGameCreated")
+	for _, player := range []string{invitation.FromName, invitation.ToName} {
+		wsHub.SendToPlayer(player, types.WebsocketMessage{
+			Type:     "gameCreated",
+			Message:  "",
+			Username: player,
+			GameId:   game.ID})
+	}
+}
+
+func declineChallenge(store database.Store, conn *hub.Conn, code string) {
+	invitation, err := store.DeclineInvitation(strings.ToUpper(strings.TrimSpace(code)), conn.Username())
+	if err != nil {
+		sendInvitationError(conn, err)
+		return
+	}
+	sendInvitation(invitation.FromName, "challengeDeclined", invitation)
+}
+
+// sendPendingChallenges delivers the challenges sent while the player was offline
+func sendPendingChallenges(store database.Store, conn *hub.Conn) {
+	challenges, err := store.GetPendingChallenges(conn.Username())
+	if err != nil {
+		fmt.Println("Failed to get pending challenges:", err)
+		return
+	}
+	for _, invitation := range challenges {
+		invitationJSON, _ := json.Marshal(invitation)
+		conn.Send(types.WebsocketMessage{
+			Type:     "challenge",
+			Message:  string(invitationJSON),
+			Username: conn.Username(),
+			GameId:   -1})
+	}
+}
+
+// expireInvitations expires the invitations past their date every interval and tells both players
+func expireInvitations(store database.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		invitations, err := store.ExpireInvitations(now)
+		if err != nil {
+			fmt.Println("Failed to expire invitations:", err)
+			continue
+		}
+		for _, invitation := range invitations {
+			sendInvitation(invitation.FromName, "invitationExpired", invitation)
+			if invitation.ToName != "" {
+				sendInvitation(invitation.ToName, "invitationExpired", invitation)
+			}
+		}
+	}
+}
+
+func sendInvitation(username string, messageType string, invitation types.Invitation) {
+	invitationJSON, _ := json.Marshal(invitation)
+	wsHub.SendToPlayer(username, types.WebsocketMessage{
+		Type:     messageType,
+		Message:  string(invitationJSON),
+		Username: username,
+		GameId:   -1})
+}
+
+func sendInvitationError(conn *hub.Conn, err error) {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		sendError(conn, -1, "Invitation not found")
+	case errors.Is(err, database.ErrInvitationClosed):
+		sendError(conn, -1, "Invitation is no longer pending")
+	case errors.Is(err, database.ErrNotInvited):
+		sendError(conn, -1, "You can't accept this invitation")
+	default:
+		fmt.Println("Failed to answer invitation:", err)
+		sendError(conn, -1, "Failed to answer invitation")
+	}
+}
+
+func sendError(conn *hub.Conn, gameId int64, message string) {
+	conn.Send(types.WebsocketMessage{
+		Type:     "error",
+		Message:  message,
+		Username: conn.Username(),
+		GameId:   gameId})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/allanlepinay/TicTacToe/backend/database"
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+const defaultPeriod = "all-time"
+
+// periodStart returns the start of the rolling period of the leaderboard, zero for all-time
+var periodStart = map[string]func(now time.Time) time.Time{
+	"all-time": func(now time.Time) time.Time { return time.Time{} },
+	"monthly":  func(now time.Time) time.Time { return now.AddDate(0, -1, 0) },
+	"weekly":   func(now time.Time) time.Time { return now.AddDate(0, 0, -7) },
+}
+
+// GetLeaderboard returns a page of the standings of the rated games finished during the period (all-time,
+// monthly or weekly) of the variant query parameters, with the standing of the requesting player
+func GetLeaderboard(store database.Store, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := types.LeaderboardFilter{Variant: query.Get("variant")}
+	filter.Player, _ = r.Context().Value("user").(string)
+	if filter.Variant != "" {
+		if _, err := gamerules.GetVariant(filter.Variant); err != nil {
+			http.Error(w, "Unknown variant", http.StatusBadRequest)
+			return
+		}
+	}
+
+	period := query.Get("period")
+	if period == "" {
+		period = defaultPeriod
+	}
+	start, ok := periodStart[period]
+	if !ok {
+		http.Error(w, "Period must be all-time, monthly or weekly", http.StatusBadRequest)
+		return
+	}
+	filter.Since = start(time.Now())
+
+	page, pageSize, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	leaderboard, err := store.GetLeaderboard(filter)
+	if err != nil {
+		fmt.Println("Failed to get leaderboard:", err)
+		http.Error(w, "Failed to get leaderboard", http.StatusInternalServerError)
+		return
+	}
+	if leaderboard.Entries == nil {
+		leaderboard.Entries = []types.LeaderboardEntry{}
+	}
+	leaderboard.Period = period
+	leaderboard.Variant = filter.Variant
+	leaderboard.Page = page
+	leaderboard.PageSize = pageSize
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(leaderboard)
+}
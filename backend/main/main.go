@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"math/rand"
 	"net/http"
+	"os"
 	"strconv"
-	"sync"
+	"strings"
+	"time"
 
+	"github.com/allanlepinay/TicTacToe/backend/ai"
 	"github.com/allanlepinay/TicTacToe/backend/auth"
 	"github.com/allanlepinay/TicTacToe/backend/database"
+	"github.com/allanlepinay/TicTacToe/backend/database/migrations"
 	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/hub"
+	"github.com/allanlepinay/TicTacToe/backend/matchmaking"
+	"github.com/allanlepinay/TicTacToe/backend/tournaments"
 	"github.com/allanlepinay/TicTacToe/backend/types"
 	"github.com/allanlepinay/TicTacToe/backend/utils"
 	"github.com/gorilla/mux"
@@ -25,20 +36,17 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-var leaveQueueMessage struct {
-	Username string `json:"username"`
-}
+const queueTimeout = 5 * time.Minute
 
-var waitingPlayers = make(chan string, 100)
-var mutex = &sync.Mutex{}
+// Interval at which the queue pairs the players whose rating windows widened and sends them their status
+const queueInterval = 5 * time.Second
 
-type Client struct {
-	username    string
-	gamesByConn map[*websocket.Conn]int64
-	connsByGame map[int64]*websocket.Conn
-}
+var queue = matchmaking.NewQueue(queueTimeout)
+
+var wsHub = hub.New()
 
-var clientsByUsername = make(map[string]*Client)
+// Set once the store is opened, broadcastGame tells it about the games ending
+var tournamentManager *tournaments.Manager
 
 func main() {
 	// .env load
@@ -48,33 +56,106 @@ func main() {
 		panic(err)
 	}
 
-	connStr := viper.GetString("DATABASE_CONN_STRING")
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		fmt.Println("Error connecting to the database:", err)
+	storage := flag.String("storage", "postgres", "storage backend: postgres or memory")
+	flag.Parse()
+
+	var store database.Store
+	switch *storage {
+	case "memory":
+		store = database.NewMemoryStore()
+	case "postgres":
+		connStr := viper.GetString("DATABASE_CONN_STRING")
+		db, err := sql.Open("postgres", connStr)
+		if err != nil {
+			fmt.Println("Error connecting to the database:", err)
+			return
+		}
+		defer db.Close()
+
+		if flag.Arg(0) == "migrate" {
+			if err := runMigrate(db, flag.Args()[1:]); err != nil {
+				fmt.Println("Migration failed:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		postgresStore := database.NewPostgresStore(db)
+		// Refuses to start if the schema is ahead of the binary
+		if err := postgresStore.Migrate(); err != nil {
+			fmt.Println("Failed to migrate the database:", err)
+			os.Exit(1)
+		}
+		store = postgresStore
+	default:
+		fmt.Println("Unknown storage:", *storage)
+		return
+	}
+
+	if err := createBots(store); err != nil {
+		fmt.Println(err)
 		return
 	}
-	defer db.Close()
+
+	go queue.Run(context.Background(), queueInterval)
+	go handleMatchmakingEvents(store)
+	go expireInvitations(store, 10*time.Second)
+
+	tournamentManager = tournaments.NewManager(store)
+	go handleTournamentEvents(tournamentManager)
+	if err := tournamentManager.Resume(); err != nil {
+		fmt.Println("Failed to resume tournaments:", err)
+	}
 
 	r := mux.NewRouter()
 	// Not protected route
 	r.HandleFunc("/register", auth.WithCORS(func(w http.ResponseWriter, r *http.Request) {
-		Register(db, w, r)
+		Register(store, w, r)
 	}))
 	r.HandleFunc("/login", auth.WithCORS(func(w http.ResponseWriter, r *http.Request) {
-		Login(db, w, r)
+		Login(store, w, r)
 	}))
 	r.HandleFunc("/refresh-token", auth.WithCORS(auth.RefreshTokenHandler))
 	// Protected route
+	r.HandleFunc("/games", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		ListGames(store, w, r)
+	}))).Methods(http.MethodGet, http.MethodOptions)
+	r.HandleFunc("/leaderboard", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		GetLeaderboard(store, w, r)
+	}))).Methods(http.MethodGet, http.MethodOptions)
+	r.HandleFunc("/tournaments", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		ListTournaments(store, w, r)
+	}))).Methods(http.MethodGet, http.MethodOptions)
+	r.HandleFunc("/tournaments", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		CreateTournament(tournamentManager, w, r)
+	}))).Methods(http.MethodPost)
+	r.HandleFunc("/tournament/{id}", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		GetTournament(tournamentManager, w, r)
+	}))).Methods(http.MethodGet, http.MethodOptions)
+	r.HandleFunc("/tournament/{id}/join", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		JoinTournament(store, w, r)
+	}))).Methods(http.MethodPost, http.MethodOptions)
+	r.HandleFunc("/tournament/{id}/leave", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		LeaveTournament(store, w, r)
+	}))).Methods(http.MethodPost, http.MethodOptions)
+	r.HandleFunc("/tournament/{id}/start", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		StartTournament(tournamentManager, w, r)
+	}))).Methods(http.MethodPost, http.MethodOptions)
 	r.HandleFunc("/game/{id}", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
-		UpdateGameBoard(db, w, r)
-	})))
+		GetGame(store, w, r)
+	}))).Methods(http.MethodGet, http.MethodOptions)
+	r.HandleFunc("/game/{id}/moves", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		GetGameMoves(store, w, r)
+	}))).Methods(http.MethodGet, http.MethodOptions)
+	r.HandleFunc("/game/{id}/analysis", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		GetGameAnalysis(store, w, r)
+	}))).Methods(http.MethodGet, http.MethodOptions)
 	r.HandleFunc("/verify-token", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"status": "valid"})
 	})))
 	r.HandleFunc("/leave-queue", auth.WithCORS(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
-		LeaveQueue(db, w, r)
+		LeaveQueue(w, r)
 	})))
 	r.HandleFunc("/ws", auth.WithCORS(func(w http.ResponseWriter, r *http.Request) {
 		token := r.URL.Query().Get("token")
@@ -83,32 +164,88 @@ func main() {
 			return
 		}
 
-		_, err := auth.ValidateToken(token)
+		validToken, err := auth.ValidateToken(token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		username, err := auth.GetUsername(validToken)
 		if err != nil {
 			http.Error(w, "Invalid token", http.StatusUnauthorized)
 			return
 		}
 
-		handleWebSocket(db, w, r)
+		handleWebSocket(store, username, w, r)
 	}))
 
 	http.ListenAndServe(":8080", r)
 }
 
-func Register(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+// runMigrate handles `migrate [up | down [steps] | version | force <version>]`
+func runMigrate(db *sql.DB, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		return migrations.Up(db)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		return migrations.Down(db, steps)
+	case "version":
+		version, err := migrations.Version(db)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Schema version:", version)
+		return nil
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("missing version to force")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		return migrations.Force(db, version)
+	default:
+		return fmt.Errorf("unknown migrate command: %s", command)
+	}
+}
+
+func Register(store database.Store, w http.ResponseWriter, r *http.Request) {
 	var player types.Player
 	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
+	if strings.HasPrefix(player.Name, ai.BotPrefix) {
+		http.Error(w, "Player names starting with "+ai.BotPrefix+" are reserved", http.StatusBadRequest)
+		return
+	}
+
 	hashedPassword, err := utils.HashPassword(player.Password)
 	if err != nil {
 		http.Error(w, "Failed to hash password", http.StatusInternalServerError)
 		return
 	}
 
-	_, err = db.Exec("INSERT INTO players (name, password_hash) VALUES ($1, $2)", player.Name, hashedPassword)
+	err = store.CreatePlayer(player.Name, hashedPassword)
+	if errors.Is(err, database.ErrPlayerExists) {
+		http.Error(w, "Player already exists", http.StatusConflict)
+		return
+	}
 	if err != nil {
 		fmt.Println(err)
 		http.Error(w, "Failed to register player", http.StatusInternalServerError)
@@ -118,7 +255,7 @@ func Register(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func Login(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+func Login(store database.Store, w http.ResponseWriter, r *http.Request) {
 	var player types.Player
 
 	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
@@ -126,8 +263,7 @@ func Login(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var storedHash string
-	err := db.QueryRow("SELECT password_hash FROM players WHERE name = $1 LIMIT 1", player.Name).Scan(&storedHash)
+	storedHash, err := store.GetPasswordHash(player.Name)
 	if err != nil {
 		http.Error(w, "Invalid name or password", http.StatusUnauthorized)
 		return
@@ -160,83 +296,158 @@ func Login(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func LeaveQueue(db *sql.DB, w http.ResponseWriter, r *http.Request) {
-	err := json.NewDecoder(r.Body).Decode(&leaveQueueMessage)
-	if err != nil {
-		http.Error(w, "Invalid request leaveQueueMessage", http.StatusBadRequest)
-		return
-	}
-
-	if leaveQueueMessage.Username == "" {
-		http.Error(w, "Username is required", http.StatusBadRequest)
+func LeaveQueue(w http.ResponseWriter, r *http.Request) {
+	username, _ := r.Context().Value("user").(string)
+	if username == "" {
+		http.Error(w, "Username is required", http.StatusUnauthorized)
 		return
 	}
 
-	removeFromQueue(leaveQueueMessage.Username)
+	queue.Cancel(username)
 
 	// Inform the client that they've been removed from the queue
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "removed from queue"})
 }
 
-func removeFromQueue(username string) {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	// Create a temporary channel to hold players we want to keep
-	tempChannel := make(chan string, len(waitingPlayers))
-
-	// Iterate through the waiting players
-	for len(waitingPlayers) > 0 {
-		player := <-waitingPlayers
-		if player != username {
-			// If the player is not the one we want to remove, add them back to the temp channel
-			tempChannel <- player
+func handleMatchmakingEvents(store database.Store) {
+	for event := range queue.Events() {
+		switch event.Type {
+		case matchmaking.EventMatched:
+			game, err := store.CreateNewGame(event.Players[0], event.Players[1], event.Variant, event.Size, true)
+			if err != nil {
+				fmt.Println("Failed to create game:", err)
+				continue
+			}
+			announceGame(store, game.ID, "lobbyGameCreated")
+			// Players bind their connection to the game with JoinGame once they receive it
+			for _, player := range event.Players {
+				wsHub.SendToPlayer(player, types.WebsocketMessage{
+					Type:     "gameCreated",
+					Message:  "",
+					Username: player,
+					GameId:   game.ID})
+			}
+		case matchmaking.EventWaiting:
+			sendQueueStatus(event.Players[0], event.Status)
+		case matchmaking.EventEvicted:
+			wsHub.SendToPlayer(event.Players[0], types.WebsocketMessage{
+				Type:     "queueTimeout",
+				Message:  "No opponent found",
+				Username: event.Players[0],
+				GameId:   -1})
 		}
 	}
+}
+
+func sendQueueStatus(username string, status types.QueueStatus) {
+	jsonStatus, err := json.Marshal(status)
+	if err != nil {
+		fmt.Println("Failed to marshal queue status:", err)
+		return
+	}
+	wsHub.SendToPlayer(username, types.WebsocketMessage{
+		Type:     "waiting",
+		Message:  string(jsonStatus),
+		Username: username,
+		GameId:   -1})
+}
 
-	// Replace the waitingPlayers channel with the temp channel
-	waitingPlayers = tempChannel
+// canViewGame tells if the user can read the game: anyone once it is over, only its players before
+func canViewGame(game types.GameDetails, username string) bool {
+	return game.Status == types.StatusTerminated || username == game.PlayerXName || username == game.PlayerOName
 }
 
-func UpdateGameBoard(db *sql.DB, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	gameId := vars["id"]
-	gameIdInt, _ := strconv.ParseInt(gameId, 0, 64)
-
-	board := database.GetBoard(db, types.Move{})
-	if board == [3][3]string{} {
-		board = [3][3]string{
-			{"", "", ""},
-			{"", "", ""},
-			{"", "", ""},
-		}
+// getGameOfRequest returns the game of the {id} route variable if the user can view it,
+// otherwise it writes the error response and returns false
+func getGameOfRequest(store database.Store, w http.ResponseWriter, r *http.Request) (types.GameDetails, bool) {
+	gameId, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid game id", http.StatusBadRequest)
+		return types.GameDetails{}, false
 	}
-	victory, _ := gamerules.CheckVictory(board)
-	turn, _ := database.GetGameTurn(db, gameIdInt)
-	var game types.Game
-	if victory {
-		game = types.Game{
-			ID:     gameIdInt,
-			Board:  board,
-			Turn:   turn,
-			Status: types.StatusTerminated,
-		}
-		database.UpdateGameStatus(db, gameIdInt, types.StatusTerminated)
-	} else {
-
-		game = types.Game{
-			ID:     gameIdInt,
-			Board:  board,
-			Turn:   turn,
-			Status: types.StatusStarted,
-		}
+
+	game, err := store.GetGameDetails(gameId)
+	if errors.Is(err, database.ErrNotFound) {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return types.GameDetails{}, false
+	}
+	if err != nil {
+		fmt.Println("Failed to get game:", err)
+		http.Error(w, "Failed to get game", http.StatusInternalServerError)
+		return types.GameDetails{}, false
 	}
 
+	username, _ := r.Context().Value("user").(string)
+	if !canViewGame(game, username) {
+		http.Error(w, "Only the players can view a game in progress", http.StatusForbidden)
+		return types.GameDetails{}, false
+	}
+	return game, true
+}
+
+func GetGame(store database.Store, w http.ResponseWriter, r *http.Request) {
+	game, ok := getGameOfRequest(store, w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(game)
 }
 
-func handleWebSocket(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+// GetGameMoves returns the moves of the game in the order they were played
+func GetGameMoves(store database.Store, w http.ResponseWriter, r *http.Request) {
+	game, ok := getGameOfRequest(store, w, r)
+	if !ok {
+		return
+	}
+
+	moves, err := store.GetMoves(game.ID)
+	if err != nil {
+		fmt.Println("Failed to get moves:", err)
+		http.Error(w, "Failed to get moves", http.StatusInternalServerError)
+		return
+	}
+	if moves == nil {
+		moves = []types.PlayedMove{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(moves)
+}
+
+// GetGameAnalysis returns the theoretical evaluation of every position of a classic game
+func GetGameAnalysis(store database.Store, w http.ResponseWriter, r *http.Request) {
+	game, ok := getGameOfRequest(store, w, r)
+	if !ok {
+		return
+	}
+
+	moves, err := store.GetMoves(game.ID)
+	if err != nil {
+		fmt.Println("Failed to get moves:", err)
+		http.Error(w, "Failed to get moves", http.StatusInternalServerError)
+		return
+	}
+
+	analysis, err := ai.Analyze(game.Game, moves)
+	if errors.Is(err, ai.ErrNotSolved) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		fmt.Println("Failed to analyze game:", err)
+		http.Error(w, "Failed to analyze game", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analysis)
+}
+
+// handleWebSocket serves the connection of username, authenticated from the token at upgrade time
+func handleWebSocket(store database.Store, username string, w http.ResponseWriter, r *http.Request) {
 	incomingConn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		fmt.Println(err)
@@ -244,6 +455,15 @@ func handleWebSocket(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 	}
 	defer incomingConn.Close()
 
+	conn := wsHub.Register(incomingConn, username)
+	defer wsHub.Unregister(conn)
+
+	sendPendingChallenges(store, conn)
+
+	// Cancels the replay streamed to the connection, if any
+	stopReplay := func() {}
+	defer func() { stopReplay() }()
+
 	for {
 		// Read client message
 		_, msg, err := incomingConn.ReadMessage()
@@ -257,86 +477,93 @@ func handleWebSocket(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		client, exists := clientsByUsername[message.Username]
-		if !exists {
-			client = &Client{
-				username:    message.Username,
-				connsByGame: make(map[int64]*websocket.Conn),
-				gamesByConn: make(map[*websocket.Conn]int64),
-			}
-			clientsByUsername[message.Username] = client
+		// The username of the frame is optional but must match the authenticated one
+		if message.Username != "" && message.Username != conn.Username() {
+			sendError(conn, message.GameId, "Username doesn't match the authenticated user")
+			continue
 		}
+		message.Username = conn.Username()
 
 		switch message.Type {
 		case "JoinQueue":
-			if client.username == "" {
-				incomingConn.WriteJSON(types.WebsocketMessage{
-					Type:     "error",
-					Message:  "No username found",
-					Username: "",
-					GameId:   -1})
-				return
+			if message.Variant == "" {
+				message.Variant = gamerules.DefaultVariant
+			}
+			if _, err := gamerules.GetVariant(message.Variant); err != nil {
+				sendError(conn, -1, "Unknown variant")
+				continue
+			}
+			size, err := gamerules.BoardSizeOf(message.Variant, message.Size)
+			if err != nil {
+				sendError(conn, -1, "Invalid board: "+err.Error())
+				continue
 			}
 
-			waitingPlayers <- client.username
-			select {
-			case player1 := <-waitingPlayers:
-				select {
-				case player2 := <-waitingPlayers:
-					if player1 != player2 {
-						clientsByUsername[player2].gamesByConn[incomingConn] = -1
-
-						game, err := database.CreateNewGame(db, player1, player2)
-						if err != nil {
-							fmt.Println("Failed to create game:", err)
-							continue
-						}
-						// Update clients with new game information
-						for _, player := range []string{player1, player2} {
-							clientConnToSend, err := getClientConnWithoutGameId(clientsByUsername[player])
-							if err != nil {
-								panic(err)
-							}
-							clientsByUsername[player].gamesByConn[clientConnToSend] = game.ID
-							clientsByUsername[player].connsByGame[game.ID] = clientConnToSend
-							clientConnToSend.WriteJSON(types.WebsocketMessage{
-								Type:     "gameCreated",
-								Message:  "",
-								Username: clientsByUsername[player].username,
-								GameId:   game.ID})
-						}
-
-					} else {
-						// Can't play with oneself
-						waitingPlayers <- player1
-
-						incomingConn.WriteJSON(types.WebsocketMessage{
-							Type:     "waiting",
-							Message:  "Can't play with oneself",
-							Username: player1,
-							GameId:   -1})
-					}
-				default:
-					// No second player yet
-					waitingPlayers <- player1
+			if status, ok := queue.Status(conn.Username()); ok {
+				sendQueueStatus(conn.Username(), status)
+				continue
+			}
 
-					clientsByUsername[player1].gamesByConn[incomingConn] = -1
+			player, err := store.GetPlayerByName(conn.Username())
+			if err != nil {
+				fmt.Println("Failed to get player:", err)
+				continue
+			}
+			err = queue.Enqueue(matchmaking.Request{
+				Username: conn.Username(),
+				Variant:  message.Variant,
+				Size:     size,
+				Rating:   player.Rating.Rating,
+				Rematch:  message.Rematch})
+			if err != nil {
+				fmt.Println("Failed to join queue:", err)
+				continue
+			}
+			// Not queued anymore when the match was emitted right away
+			if status, ok := queue.Status(conn.Username()); ok {
+				sendQueueStatus(conn.Username(), status)
+			}
+		case "StartBotGame":
+			// The level of the bot is the message, perfect by default
+			if message.Message == "" {
+				message.Message = ai.LevelName[ai.LevelPerfect]
+			}
+			level, err := ai.ParseLevel(message.Message)
+			if err != nil {
+				sendError(conn, -1, "Unknown bot level")
+				continue
+			}
+			if message.Variant == "" {
+				message.Variant = gamerules.DefaultVariant
+			}
+			size, err := gamerules.BoardSizeOf(message.Variant, message.Size)
+			if err != nil {
+				sendError(conn, -1, "Invalid board: "+err.Error())
+				continue
+			}
 
-					incomingConn.WriteJSON(types.WebsocketMessage{
-						Type:     "waiting",
-						Message:  "",
-						Username: player1,
-						GameId:   -1})
-				}
-			default:
-				incomingConn.WriteJSON(types.WebsocketMessage{
-					Type:     "waiting",
-					Message:  "",
-					Username: "",
-					GameId:   -1})
+			// The player gets X or O at random
+			playerX, playerO := conn.Username(), ai.BotName(level)
+			if rand.Intn(2) == 0 {
+				playerX, playerO = playerO, playerX
 			}
+			// Bots aren't rated
+			game, err := store.CreateNewGame(playerX, playerO, message.Variant, size, false)
+			if err != nil {
+				fmt.Println("Failed to create bot game:", err)
+				sendError(conn, -1, "Failed to create game")
+				continue
+			}
+			announceGame(store, game.ID, "lobbyGameCreated")
+			conn.Send(types.WebsocketMessage{
+				Type:     "gameCreated",
+				Message:  "",
+				Username: conn.Username(),
+				GameId:   game.ID})
+			// Plays first if it got X, the player sees the move when joining the game
+			go playBotTurn(store, game.ID)
 		case "ping":
-			incomingConn.WriteJSON(types.WebsocketMessage{
+			conn.Send(types.WebsocketMessage{
 				Type:     "message",
 				Message:  "pong",
 				Username: "",
@@ -354,49 +581,113 @@ func handleWebSocket(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 					fmt.Println("Error unmarshalling move:", err)
 					continue
 				}
-				game := database.MakeMove(db, move)
-
-				players, _ := database.GetPlayersByGameId(db, move.GameId)
-
-				// Send game to players (only concerned player for the right gameId)
-				for _, player := range players {
-					gameJSON, _ := json.Marshal(game)
-					conn := clientsByUsername[player.Name].connsByGame[message.GameId]
-					conn.WriteJSON(types.WebsocketMessage{
-						Type:     "move",
-						Message:  string(gameJSON),
-						Username: clientsByUsername[player.Name].username,
-						GameId:   game.ID,
-					})
-					// update clients
-					if game.Status == 2 {
-						delete(clientsByUsername[player.Name].gamesByConn, conn)
-						delete(clientsByUsername[player.Name].connsByGame, game.ID)
+				game, err := store.MakeMove(move)
+				if err != nil {
+					var moveErr *gamerules.MoveError
+					reason := "Failed to make move"
+					if errors.As(err, &moveErr) {
+						reason = moveErr.Reason
+					} else {
+						fmt.Println("Failed to make move:", err)
 					}
+					sendError(conn, message.GameId, reason)
+					continue
 				}
+
+				// Send game to players (only connections bound to this game)
+				broadcastGame(store, game, conn.Username())
+				go playBotTurn(store, game.ID)
+			}
+		case "JoinGame":
+			game, err := store.GetGame(message.GameId)
+			if err != nil {
+				sendError(conn, message.GameId, "Game not found")
+				continue
+			}
+
+			player, err := store.GetPlayerByName(conn.Username())
+			if err != nil || (player.ID != game.PlayerXId && player.ID != game.PlayerOId) {
+				sendError(conn, message.GameId, "Not a player of this game")
+				continue
 			}
+
+			// Rebind the game to this connection, the previous one is likely dead (page refresh, new tab)
+			wsHub.BindGame(conn, game.ID)
+
+			gameJSON, _ := json.Marshal(game)
+			conn.Send(types.WebsocketMessage{
+				Type:     "move",
+				Message:  string(gameJSON),
+				Username: conn.Username(),
+				GameId:   game.ID,
+			})
+			conn.Send(types.SpectatorCount{Type: "spectators", GameId: game.ID, Count: wsHub.SpectatorCount(game.ID)})
+		case "Spectate":
+			game, err := store.GetGame(message.GameId)
+			if err != nil {
+				sendError(conn, message.GameId, "Game not found")
+				continue
+			}
+
+			player, err := store.GetPlayerByName(conn.Username())
+			if err == nil && (player.ID == game.PlayerXId || player.ID == game.PlayerOId) {
+				sendError(conn, message.GameId, "You are a player of this game")
+				continue
+			}
+
+			gameJSON, _ := json.Marshal(game)
+			conn.Send(types.WebsocketMessage{
+				Type:     "move",
+				Message:  string(gameJSON),
+				Username: conn.Username(),
+				GameId:   game.ID,
+			})
+			// Nothing more will happen in a finished game
+			if game.Status != types.StatusTerminated {
+				wsHub.Spectate(conn, game.ID)
+			}
+		case "StopSpectating":
+			wsHub.StopSpectating(conn, message.GameId)
+		case "replay":
+			// Only one replay at a time per connection
+			stopReplay()
+			ctx, cancel := context.WithCancel(context.Background())
+			stopReplay = cancel
+			if err := startReplay(ctx, store, conn, message.GameId, message.Message); err != nil {
+				sendError(conn, message.GameId, err.Error())
+			}
+		case "StopReplay":
+			stopReplay()
+		case "CreatePrivateGame":
+			if message.Variant == "" {
+				message.Variant = gamerules.DefaultVariant
+			}
+			createPrivateGame(store, conn, message.Variant, message.Size, message.Rated)
+		case "JoinByCode", "AcceptChallenge":
+			// The code is the message
+			acceptInvitation(store, conn, message.Message)
+		case "Challenge":
+			// The challenged player is the message
+			if message.Variant == "" {
+				message.Variant = gamerules.DefaultVariant
+			}
+			challenge(store, conn, message.Message, message.Variant, message.Size, message.Rated)
+		case "DeclineChallenge":
+			declineChallenge(store, conn, message.Message)
+		case "SubscribeLobby":
+			wsHub.SubscribeLobby(conn)
+		case "UnsubscribeLobby":
+			wsHub.UnsubscribeLobby(conn)
 		case "getPlayerProfile":
 			var playerIdMap map[string]string
 			json.Unmarshal([]byte(message.Message), &playerIdMap)
-			player, games, err := database.GetPlayerProfile(db, playerIdMap["playerId"])
+			profile, err := store.GetPlayerProfile(playerIdMap["playerId"])
 			if err != nil {
 				fmt.Println("Failed to get player profile:", err)
 				continue
 			}
-			incomingConn.WriteJSON(types.PlayerProfile{
-				Type:   "playerProfile",
-				Player: player,
-				Games:  games,
-			})
-		}
-	}
-}
-
-func getClientConnWithoutGameId(client *Client) (*websocket.Conn, error) {
-	for conn, gameId := range client.gamesByConn {
-		if gameId == -1 {
-			return conn, nil
+			profile.Type = "playerProfile"
+			conn.Send(profile)
 		}
 	}
-	return nil, fmt.Errorf("no connection found without a game ID")
 }
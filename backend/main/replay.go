@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/allanlepinay/TicTacToe/backend/database"
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/hub"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+const (
+	// Time between two steps of a replay at speed 1
+	replayInterval  = time.Second
+	minReplaySpeed  = 0.25
+	maxReplaySpeed  = 16
+	errReplayFailed = "Failed to replay game"
+)
+
+// startReplay streams the states of the game to the connection of a user who can view it until ctx is canceled.
+// speed is a multiplier of the default pace, 1 if empty
+func startReplay(ctx context.Context, store database.Store, conn *hub.Conn, gameId int64, speed string) error {
+	pace := 1.0
+	if speed != "" {
+		var err error
+		pace, err = strconv.ParseFloat(speed, 64)
+		if err != nil || pace < minReplaySpeed || pace > maxReplaySpeed {
+			return fmt.Errorf("speed must be between %g and %g", float64(minReplaySpeed), float64(maxReplaySpeed))
+		}
+	}
+
+	game, err := store.GetGameDetails(gameId)
+	if errors.Is(err, database.ErrNotFound) {
+		return errors.New("Game not found")
+	}
+	if err != nil {
+		fmt.Println("Failed to get game:", err)
+		return errors.New(errReplayFailed)
+	}
+	if !canViewGame(game, conn.Username()) {
+		return errors.New("Only the players can view a game in progress")
+	}
+
+	moves, err := store.GetMoves(gameId)
+	if err != nil {
+		fmt.Println("Failed to get moves:", err)
+		return errors.New(errReplayFailed)
+	}
+	states, err := gamerules.Replay(game.Variant, game.BoardSize, moves)
+	if err != nil {
+		fmt.Println("Failed to replay game:", err)
+		return errors.New(errReplayFailed)
+	}
+
+	go streamReplay(ctx, conn, game.Game, moves, states, time.Duration(float64(replayInterval)/pace))
+	return nil
+}
+
+func streamReplay(ctx context.Context, conn *hub.Conn, game types.Game, moves []types.PlayedMove, states []types.Game, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ply, state := range states {
+		if ply > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+
+		state.ID = game.ID
+		state.PlayerXId = game.PlayerXId
+		state.PlayerOId = game.PlayerOId
+		step := types.ReplayStep{Ply: int64(ply), Game: state}
+		if ply > 0 {
+			step.Move = &moves[ply-1]
+		}
+		stepJSON, _ := json.Marshal(step)
+		conn.Send(types.WebsocketMessage{
+			Type:     "replay",
+			Message:  string(stepJSON),
+			Username: conn.Username(),
+			GameId:   game.ID,
+		})
+	}
+
+	conn.Send(types.WebsocketMessage{
+		Type:     "replayEnd",
+		Message:  "",
+		Username: conn.Username(),
+		GameId:   game.ID,
+	})
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/allanlepinay/TicTacToe/backend/ai"
+	"github.com/allanlepinay/TicTacToe/backend/database"
+	gamerules "github.com/allanlepinay/TicTacToe/backend/gameRules"
+	"github.com/allanlepinay/TicTacToe/backend/tournaments"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+	"github.com/gorilla/mux"
+)
+
+type createTournamentRequest struct {
+	Name    string `json:"name"`
+	Format  string `json:"format"`
+	Variant string `json:"variant"`
+	// Board of the games, the one of the variant if zero
+	Size  types.BoardSize `json:"size"`
+	Rated bool            `json:"rated"`
+}
+
+func ListTournaments(store database.Store, w http.ResponseWriter, r *http.Request) {
+	list, err := store.ListTournaments()
+	if err != nil {
+		fmt.Println("Failed to list tournaments:", err)
+		http.Error(w, "Failed to list tournaments", http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []types.Tournament{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// CreateTournament opens the registration of a tournament created by the requesting player, who doesn't play it
+// unless they join it
+func CreateTournament(manager *tournaments.Manager, w http.ResponseWriter, r *http.Request) {
+	var request createTournamentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+	if request.Variant != "" {
+		if _, err := gamerules.GetVariant(request.Variant); err != nil {
+			http.Error(w, "Unknown variant", http.StatusBadRequest)
+			return
+		}
+	}
+	if _, err := gamerules.BoardSizeOf(request.Variant, request.Size); err != nil {
+		http.Error(w, "Invalid board: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username, _ := r.Context().Value("user").(string)
+	tournament, err := manager.Create(request.Name, username, request.Format, request.Variant, request.Size, request.Rated)
+	if errors.Is(err, tournaments.ErrUnknownFormat) {
+		http.Error(w, "Unknown format", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		fmt.Println("Failed to create tournament:", err)
+		http.Error(w, "Failed to create tournament", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tournament)
+}
+
+// GetTournament returns the tournament with its games and standings
+func GetTournament(manager *tournaments.Manager, w http.ResponseWriter, r *http.Request) {
+	tournamentId, ok := getTournamentIdOfRequest(w, r)
+	if !ok {
+		return
+	}
+
+	details, err := manager.Details(tournamentId)
+	if err != nil {
+		writeTournamentError(w, err, "get tournament")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(details)
+}
+
+func JoinTournament(store database.Store, w http.ResponseWriter, r *http.Request) {
+	tournamentId, ok := getTournamentIdOfRequest(w, r)
+	if !ok {
+		return
+	}
+	username, _ := r.Context().Value("user").(string)
+	if _, isBot := ai.BotLevel(username); isBot {
+		http.Error(w, "Bots can't join tournaments", http.StatusForbidden)
+		return
+	}
+
+	tournament, err := store.JoinTournament(tournamentId, username)
+	if err != nil {
+		writeTournamentError(w, err, "join tournament")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tournament)
+}
+
+func LeaveTournament(store database.Store, w http.ResponseWriter, r *http.Request) {
+	tournamentId, ok := getTournamentIdOfRequest(w, r)
+	if !ok {
+		return
+	}
+	username, _ := r.Context().Value("user").(string)
+
+	tournament, err := store.LeaveTournament(tournamentId, username)
+	if err != nil {
+		writeTournamentError(w, err, "leave tournament")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tournament)
+}
+
+// StartTournament closes the registration and starts the first round, only the creator can start it
+func StartTournament(manager *tournaments.Manager, w http.ResponseWriter, r *http.Request) {
+	tournamentId, ok := getTournamentIdOfRequest(w, r)
+	if !ok {
+		return
+	}
+	username, _ := r.Context().Value("user").(string)
+
+	tournament, err := manager.Start(tournamentId, username)
+	if err != nil {
+		writeTournamentError(w, err, "start tournament")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tournament)
+}
+
+// getTournamentIdOfRequest returns the {id} route variable, otherwise it writes the error response and returns false
+func getTournamentIdOfRequest(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	tournamentId, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid tournament id", http.StatusBadRequest)
+		return 0, false
+	}
+	return tournamentId, true
+}
+
+// writeTournamentError writes the response of an error returned while trying to action a tournament
+func writeTournamentError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		http.Error(w, "Tournament not found", http.StatusNotFound)
+	case errors.Is(err, tournaments.ErrNotCreator):
+		http.Error(w, "Only the creator can start the tournament", http.StatusForbidden)
+	case errors.Is(err, database.ErrRegistrationClosed):
+		http.Error(w, "The registration is closed", http.StatusConflict)
+	case errors.Is(err, database.ErrAlreadyRegistered):
+		http.Error(w, "Already registered", http.StatusConflict)
+	case errors.Is(err, database.ErrNotRegistered):
+		http.Error(w, "Not registered", http.StatusConflict)
+	case errors.Is(err, tournaments.ErrNotEnoughPlayers):
+		http.Error(w, "At least 2 players are needed", http.StatusConflict)
+	default:
+		fmt.Println("Failed to "+action+":", err)
+		http.Error(w, "Failed to "+action, http.StatusInternalServerError)
+	}
+}
+
+// handleTournamentEvents tells the players their tournament games are ready and when their tournament is over
+func handleTournamentEvents(manager *tournaments.Manager) {
+	for event := range manager.Events() {
+		switch event.Type {
+		case tournaments.EventGameReady:
+			gameJSON, _ := json.Marshal(event.Game)
+			// Players bind their connection to the game with JoinGame like for queue games
+			for _, player := range event.Players {
+				wsHub.SendToPlayer(player, types.WebsocketMessage{
+					Type:     "tournamentGameReady",
+					Message:  string(gameJSON),
+					Username: player,
+					GameId:   event.Game.GameId})
+			}
+		case tournaments.EventFinished:
+			for _, player := range event.Players {
+				wsHub.SendToPlayer(player, types.WebsocketMessage{
+					Type:     "tournamentFinished",
+					Message:  strconv.FormatInt(event.TournamentId, 10),
+					Username: player,
+					GameId:   -1})
+			}
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package matchmaking
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+var ErrAlreadyQueued = errors.New("player is already in the queue")
+
+const (
+	// Rating difference accepted by a player who just joined, growing by WindowGrowth every second of wait
+	BaseWindow   = 100
+	WindowGrowth = 5
+	// Weight of the last match in the average wait used for estimations
+	waitSmoothing = 0.2
+	// How long after a match joining the queue again avoids the same opponent
+	rematchWindow = 10 * time.Minute
+)
+
+type EventType int
+
+const (
+	EventMatched = iota
+	EventEvicted
+	EventWaiting
+)
+
+// Event is emitted by the queue, Players holds both players (X then O) for EventMatched,
+// the evicted player for EventEvicted and the waiting player, with its Status, for EventWaiting
+type Event struct {
+	Type    EventType
+	Players []string
+	Variant string
+	Size    types.BoardSize
+	Status  types.QueueStatus
+}
+
+// Request is a player joining the queue
+type Request struct {
+	Username string
+	Variant  string
+	// Board of the variant to play on, players are only paired with the ones asking for the same
+	Size   types.BoardSize
+	Rating float64
+	// Accept to play the last opponent again, only if they accept too
+	Rematch bool
+}
+
+type entry struct {
+	Request
+	joinedAt time.Time
+	// Opponent of the match the player had just before joining, if recent
+	lastOpponent string
+}
+
+// pool is what players must ask for alike to be paired
+type pool struct {
+	variant string
+	size    types.BoardSize
+}
+
+func poolOf(request Request) pool {
+	return pool{variant: request.Variant, size: request.Size}
+}
+
+type lastMatch struct {
+	opponent  string
+	matchedAt time.Time
+}
+
+// Queue pairs players waiting for the same variant and board whose ratings are close enough, the acceptable difference
+// widening with the wait. It is safe for concurrent use
+type Queue struct {
+	mutex   sync.Mutex
+	entries []entry
+	// Last match of the players, forgotten after the rematch window
+	lastMatches map[string]lastMatch
+	// Moving average of the wait before a match, per variant and board
+	averageWaits map[pool]time.Duration
+	timeout      time.Duration
+	events       chan Event
+	now          func() time.Time
+}
+
+// NewQueue creates a queue evicting players waiting longer than timeout, a zero timeout disables eviction
+func NewQueue(timeout time.Duration) *Queue {
+	return &Queue{
+		lastMatches:  make(map[string]lastMatch),
+		averageWaits: make(map[pool]time.Duration),
+		timeout:      timeout,
+		events:       make(chan Event, 100),
+		now:          time.Now,
+	}
+}
+
+// Events returns the channel on which matches, evictions and statuses are emitted
+func (q *Queue) Events() <-chan Event {
+	return q.events
+}
+
+// Enqueue adds the player to the queue and pairs them right away if a compatible player is waiting
+func (q *Queue) Enqueue(request Request) error {
+	q.mutex.Lock()
+	if q.indexOf(request.Username) != -1 {
+		q.mutex.Unlock()
+		return ErrAlreadyQueued
+	}
+	now := q.now()
+	e := entry{Request: request, joinedAt: now}
+	// Kept until the next match so cancelling and joining again still avoids the opponent
+	if last, ok := q.lastMatches[request.Username]; ok && now.Sub(last.matchedAt) < rematchWindow {
+		e.lastOpponent = last.opponent
+	}
+	q.entries = append(q.entries, e)
+	matches := q.match()
+	q.mutex.Unlock()
+
+	q.emit(matches)
+	return nil
+}
+
+// Match pairs the waiting players whose windows have grown enough to accept each other
+func (q *Queue) Match() {
+	q.mutex.Lock()
+	matches := q.match()
+	q.mutex.Unlock()
+
+	q.emit(matches)
+}
+
+// match pairs, from the oldest, each player with the compatible player of the closest rating.
+// It must be called with the mutex held
+func (q *Queue) match() []Event {
+	now := q.now()
+	var matches []Event
+	for i := 0; i < len(q.entries); i++ {
+		best := -1
+		for j := i + 1; j < len(q.entries); j++ {
+			if !q.compatible(q.entries[i], q.entries[j], now) {
+				continue
+			}
+			if best == -1 || ratingDifference(q.entries[i], q.entries[j]) < ratingDifference(q.entries[i], q.entries[best]) {
+				best = j
+			}
+		}
+		if best == -1 {
+			continue
+		}
+
+		// The oldest player plays X
+		first, second := q.entries[i], q.entries[best]
+		matches = append(matches, Event{Type: EventMatched, Players: []string{first.Username, second.Username}, Variant: first.Variant, Size: first.Size})
+		q.lastMatches[first.Username] = lastMatch{opponent: second.Username, matchedAt: now}
+		q.lastMatches[second.Username] = lastMatch{opponent: first.Username, matchedAt: now}
+		q.recordWait(poolOf(first.Request), now.Sub(first.joinedAt))
+		q.recordWait(poolOf(second.Request), now.Sub(second.joinedAt))
+
+		q.entries = append(q.entries[:best], q.entries[best+1:]...)
+		q.entries = append(q.entries[:i], q.entries[i+1:]...)
+		i--
+	}
+	return matches
+}
+
+// compatible tells if both players accept each other: same variant and board, ratings within both windows
+// and not a rematch unless both asked for it
+func (q *Queue) compatible(a entry, b entry, now time.Time) bool {
+	if poolOf(a.Request) != poolOf(b.Request) {
+		return false
+	}
+	isRematch := a.lastOpponent == b.Username || b.lastOpponent == a.Username
+	if isRematch && !(a.Rematch && b.Rematch) {
+		return false
+	}
+	difference := ratingDifference(a, b)
+	return difference <= window(now.Sub(a.joinedAt)) && difference <= window(now.Sub(b.joinedAt))
+}
+
+// window is the rating difference accepted after waiting for waited
+func window(waited time.Duration) float64 {
+	return BaseWindow + WindowGrowth*waited.Seconds()
+}
+
+func ratingDifference(a entry, b entry) float64 {
+	return math.Abs(a.Rating - b.Rating)
+}
+
+// recordWait must be called with the mutex held
+func (q *Queue) recordWait(p pool, waited time.Duration) {
+	average, ok := q.averageWaits[p]
+	if !ok {
+		q.averageWaits[p] = waited
+		return
+	}
+	q.averageWaits[p] = time.Duration(waitSmoothing*float64(waited) + (1-waitSmoothing)*float64(average))
+}
+
+func (q *Queue) emit(events []Event) {
+	for _, event := range events {
+		q.events <- event
+	}
+}
+
+// Status returns the position of the player among the players waiting for the same variant and board and the estimated
+// remaining wait, false if the player isn't queued
+func (q *Queue) Status(username string) (types.QueueStatus, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	i := q.indexOf(username)
+	if i == -1 {
+		return types.QueueStatus{}, false
+	}
+	return q.status(i, q.now()), true
+}
+
+// status returns the status of the entry at index i, entries being in the order of arrival.
+// It must be called with the mutex held
+func (q *Queue) status(i int, now time.Time) types.QueueStatus {
+	e := q.entries[i]
+	status := types.QueueStatus{EstimatedWait: -1}
+	for _, other := range q.entries[:i+1] {
+		if poolOf(other.Request) == poolOf(e.Request) {
+			status.Position++
+		}
+	}
+	if average, ok := q.averageWaits[poolOf(e.Request)]; ok {
+		status.EstimatedWait = math.Max(0, (average - now.Sub(e.joinedAt)).Seconds())
+	}
+	return status
+}
+
+// SendStatuses emits an EventWaiting for every waiting player
+func (q *Queue) SendStatuses() {
+	q.mutex.Lock()
+	now := q.now()
+	events := make([]Event, 0, len(q.entries))
+	for i, e := range q.entries {
+		events = append(events, Event{Type: EventWaiting, Players: []string{e.Username}, Variant: e.Variant, Size: e.Size, Status: q.status(i, now)})
+	}
+	q.mutex.Unlock()
+
+	q.emit(events)
+}
+
+// Cancel removes the player from the queue, it returns false if the player wasn't queued
+func (q *Queue) Cancel(username string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	i := q.indexOf(username)
+	if i == -1 {
+		return false
+	}
+	q.entries = append(q.entries[:i], q.entries[i+1:]...)
+	return true
+}
+
+// EvictExpired removes the players waiting for longer than the timeout and emits an EventEvicted for each.
+// It also forgets the matches older than the rematch window
+func (q *Queue) EvictExpired() []string {
+	q.mutex.Lock()
+	for username, last := range q.lastMatches {
+		if q.now().Sub(last.matchedAt) >= rematchWindow {
+			delete(q.lastMatches, username)
+		}
+	}
+	if q.timeout <= 0 {
+		q.mutex.Unlock()
+		return nil
+	}
+
+	var evicted []string
+	kept := q.entries[:0]
+	for _, e := range q.entries {
+		if q.now().Sub(e.joinedAt) >= q.timeout {
+			evicted = append(evicted, e.Username)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	q.entries = kept
+	q.mutex.Unlock()
+
+	for _, username := range evicted {
+		q.events <- Event{Type: EventEvicted, Players: []string{username}}
+	}
+	return evicted
+}
+
+// Run evicts expired players, pairs the players whose windows widened and sends their status to the players
+// still waiting every interval until ctx is done
+func (q *Queue) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.EvictExpired()
+			q.Match()
+			q.SendStatuses()
+		}
+	}
+}
+
+// indexOf must be called with the mutex held
+func (q *Queue) indexOf(username string) int {
+	for i, e := range q.entries {
+		if e.Username == username {
+			return i
+		}
+	}
+	return -1
+}
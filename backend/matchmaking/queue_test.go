@@ -0,0 +1,327 @@
+package matchmaking
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// fakeClock is the time of a test queue, only moved by the test
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestQueue(timeout time.Duration) (*Queue, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	q := NewQueue(timeout)
+	q.now = func() time.Time { return clock.now }
+	return q, clock
+}
+
+func enqueue(t *testing.T, q *Queue, username string, rating float64) {
+	t.Helper()
+	if err := q.Enqueue(Request{Username: username, Variant: "classic", Rating: rating}); err != nil {
+		t.Fatalf("Enqueue(%s) = %v", username, err)
+	}
+}
+
+func nextEvent(t *testing.T, q *Queue) Event {
+	t.Helper()
+	select {
+	case event := <-q.Events():
+		return event
+	default:
+		t.Fatal("no event emitted")
+		return Event{}
+	}
+}
+
+func assertNoEvent(t *testing.T, q *Queue) {
+	t.Helper()
+	select {
+	case event := <-q.Events():
+		t.Fatalf("unexpected event %+v", event)
+	default:
+	}
+}
+
+func assertMatched(t *testing.T, q *Queue, players ...string) {
+	t.Helper()
+	event := nextEvent(t, q)
+	if event.Type != EventMatched || !slices.Equal(event.Players, players) {
+		t.Fatalf("event = %+v, want %v matched", event, players)
+	}
+}
+
+func TestQueuePairsInArrivalOrder(t *testing.T) {
+	q, _ := newTestQueue(0)
+
+	enqueue(t, q, "a", 1500)
+	assertNoEvent(t, q)
+	enqueue(t, q, "b", 2000)
+	assertNoEvent(t, q)
+	// The oldest player plays X
+	enqueue(t, q, "c", 1500)
+	assertMatched(t, q, "a", "c")
+	enqueue(t, q, "d", 2000)
+	assertMatched(t, q, "b", "d")
+
+	if _, ok := q.Status("a"); ok {
+		t.Error("a is still queued after their match")
+	}
+}
+
+func TestQueueWidensTheWindowWithTheWait(t *testing.T) {
+	q, clock := newTestQueue(0)
+
+	enqueue(t, q, "a", 1500)
+	enqueue(t, q, "b", 1700)
+	assertNoEvent(t, q)
+
+	clock.advance(10 * time.Second)
+	q.Match()
+	assertNoEvent(t, q)
+
+	// Both windows reach 200 after 20 seconds
+	clock.advance(10 * time.Second)
+	q.Match()
+	assertMatched(t, q, "a", "b")
+}
+
+func TestQueueOnlyPairsTheSameVariant(t *testing.T) {
+	q, _ := newTestQueue(0)
+
+	enqueue(t, q, "a", 1500)
+	if err := q.Enqueue(Request{Username: "b", Variant: "gomoku", Rating: 1500}); err != nil {
+		t.Fatal(err)
+	}
+	assertNoEvent(t, q)
+	status, _ := q.Status("b")
+	if status.Position != 1 {
+		t.Errorf("position of b = %d, want 1 among the gomoku players", status.Position)
+	}
+}
+
+func TestEnqueueTwice(t *testing.T) {
+	q, _ := newTestQueue(0)
+
+	enqueue(t, q, "a", 1500)
+	enqueue(t, q, "b", 3000)
+	err := q.Enqueue(Request{Username: "a", Variant: "classic", Rating: 1500})
+	if !errors.Is(err, ErrAlreadyQueued) {
+		t.Fatalf("second Enqueue = %v, want ErrAlreadyQueued", err)
+	}
+	assertNoEvent(t, q)
+
+	status, ok := q.Status("a")
+	if !ok || status.Position != 1 {
+		t.Errorf("Status(a) = %+v, %v, want position 1", status, ok)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	q, _ := newTestQueue(0)
+
+	enqueue(t, q, "a", 1500)
+	if !q.Cancel("a") {
+		t.Fatal("Cancel(a) = false, want true")
+	}
+	if q.Cancel("a") {
+		t.Error("second Cancel(a) = true, want false")
+	}
+	if _, ok := q.Status("a"); ok {
+		t.Error("a is still queued after cancelling")
+	}
+
+	enqueue(t, q, "b", 1500)
+	assertNoEvent(t, q)
+	// A cancelled player can queue again
+	enqueue(t, q, "a", 1500)
+	assertMatched(t, q, "b", "a")
+}
+
+func TestEvictExpired(t *testing.T) {
+	q, clock := newTestQueue(30 * time.Second)
+
+	enqueue(t, q, "a", 1500)
+	clock.advance(20 * time.Second)
+	enqueue(t, q, "b", 3000)
+
+	clock.advance(9 * time.Second)
+	if evicted := q.EvictExpired(); len(evicted) != 0 {
+		t.Fatalf("evicted %v before the timeout", evicted)
+	}
+
+	clock.advance(time.Second)
+	if evicted := q.EvictExpired(); !slices.Equal(evicted, []string{"a"}) {
+		t.Fatalf("evicted %v, want [a]", evicted)
+	}
+	event := nextEvent(t, q)
+	if event.Type != EventEvicted || !slices.Equal(event.Players, []string{"a"}) {
+		t.Fatalf("event = %+v, want a evicted", event)
+	}
+	if _, ok := q.Status("b"); !ok {
+		t.Fatal("b was evicted before their timeout")
+	}
+
+	clock.advance(20 * time.Second)
+	if evicted := q.EvictExpired(); !slices.Equal(evicted, []string{"b"}) {
+		t.Fatalf("evicted %v, want [b]", evicted)
+	}
+}
+
+func TestEvictExpiredWithoutTimeout(t *testing.T) {
+	q, clock := newTestQueue(0)
+
+	enqueue(t, q, "a", 1500)
+	clock.advance(24 * time.Hour)
+	if evicted := q.EvictExpired(); len(evicted) != 0 {
+		t.Fatalf("evicted %v without timeout", evicted)
+	}
+}
+
+// Run with -race: players join and leave at the same time, each is matched at most once
+// and never while they are still queued
+func TestConcurrentEnqueueCancel(t *testing.T) {
+	q, _ := newTestQueue(0)
+
+	matched := make(map[string]int)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range q.events {
+			for _, player := range event.Players {
+				matched[player]++
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			username := fmt.Sprintf("player%d", i)
+			if err := q.Enqueue(Request{Username: username, Variant: "classic", Rating: 1500}); err != nil {
+				t.Errorf("Enqueue(%s) = %v", username, err)
+			}
+			if i%3 == 0 {
+				q.Cancel(username)
+			}
+			q.Status(username)
+		}(i)
+	}
+	wg.Wait()
+	close(q.events)
+	<-done
+
+	for i := 0; i < 200; i++ {
+		username := fmt.Sprintf("player%d", i)
+		_, queued := q.Status(username)
+		switch {
+		case matched[username] > 1:
+			t.Errorf("%s matched %d times", username, matched[username])
+		case matched[username] == 1 && queued:
+			t.Errorf("%s is still queued after their match", username)
+		}
+	}
+}
+
+func TestQueueAvoidsRematches(t *testing.T) {
+	q, clock := newTestQueue(0)
+
+	enqueue(t, q, "a", 1500)
+	enqueue(t, q, "b", 1500)
+	assertMatched(t, q, "a", "b")
+
+	enqueue(t, q, "a", 1500)
+	enqueue(t, q, "b", 1500)
+	assertNoEvent(t, q)
+
+	// Leaving and joining again doesn't forget the last opponent
+	q.Cancel("a")
+	q.Cancel("b")
+	enqueue(t, q, "a", 1500)
+	enqueue(t, q, "b", 1500)
+	assertNoEvent(t, q)
+
+	// A new opponent is paired right away
+	enqueue(t, q, "c", 1500)
+	assertMatched(t, q, "a", "c")
+	q.Cancel("b")
+
+	// Both asked for the rematch
+	q.Enqueue(Request{Username: "a", Variant: "classic", Rating: 1500, Rematch: true})
+	q.Enqueue(Request{Username: "c", Variant: "classic", Rating: 1500})
+	assertNoEvent(t, q)
+	q.Cancel("c")
+	q.Enqueue(Request{Username: "c", Variant: "classic", Rating: 1500, Rematch: true})
+	assertMatched(t, q, "a", "c")
+
+	// The last match is forgotten after the rematch window
+	clock.advance(rematchWindow)
+	q.EvictExpired()
+	enqueue(t, q, "a", 1500)
+	enqueue(t, q, "c", 1500)
+	assertMatched(t, q, "a", "c")
+}
+
+func TestQueueEstimatedWait(t *testing.T) {
+	q, clock := newTestQueue(0)
+
+	enqueue(t, q, "a", 1500)
+	status, _ := q.Status("a")
+	if status.EstimatedWait != -1 {
+		t.Errorf("estimated wait without matches = %v, want -1", status.EstimatedWait)
+	}
+
+	// Both windows reach 200 after 20 seconds
+	enqueue(t, q, "b", 1700)
+	clock.advance(20 * time.Second)
+	q.Match()
+	assertMatched(t, q, "a", "b")
+
+	enqueue(t, q, "c", 1500)
+	clock.advance(5 * time.Second)
+	status, _ = q.Status("c")
+	if status.EstimatedWait != 15 {
+		t.Errorf("estimated wait = %v, want 15", status.EstimatedWait)
+	}
+	clock.advance(time.Minute)
+	status, _ = q.Status("c")
+	if status.EstimatedWait != 0 {
+		t.Errorf("estimated wait past the average = %v, want 0", status.EstimatedWait)
+	}
+
+	// Each wait moves the average by a fifth of the difference: 65 seconds for c to 29, none for d to 23.2
+	enqueue(t, q, "d", 1500)
+	assertMatched(t, q, "c", "d")
+	enqueue(t, q, "e", 1500)
+	status, _ = q.Status("e")
+	if status.EstimatedWait != 23.2 {
+		t.Errorf("estimated wait = %v, want 23.2", status.EstimatedWait)
+	}
+
+	// Other variants and boards have their own average
+	for _, request := range []Request{
+		{Username: "f", Variant: "gomoku", Rating: 1500},
+		{Username: "g", Variant: "classic", Size: types.BoardSize{Width: 4, Height: 4, WinLength: 3}, Rating: 1500},
+	} {
+		if err := q.Enqueue(request); err != nil {
+			t.Fatal(err)
+		}
+		status, _ = q.Status(request.Username)
+		if status.EstimatedWait != -1 {
+			t.Errorf("estimated wait of %s = %v, want -1", request.Username, status.EstimatedWait)
+		}
+	}
+}
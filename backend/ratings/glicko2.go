@@ -0,0 +1,124 @@
+package ratings
+
+import (
+	"math"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// Glicko-2 as described in http://www.glicko.net/glicko/glicko2.pdf, every game being its own rating period
+
+const (
+	DefaultRating     = 1500
+	DefaultDeviation  = 350
+	DefaultVolatility = 0.06
+
+	// Conversion between the Glicko and the Glicko-2 scales
+	scale = 173.7178
+	// Constrains the change of volatility, between 0.3 and 1.2
+	tau = 0.5
+	// Convergence tolerance of the volatility
+	epsilon = 0.000001
+)
+
+// Result is the score against an opponent: 1 for a win, 0.5 for a draw, 0 for a loss
+type Result struct {
+	Opponent types.Rating
+	Score    float64
+}
+
+func Default() types.Rating {
+	return types.Rating{Rating: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility}
+}
+
+// Update returns the rating of the player after the results of a rating period
+func Update(player types.Rating, results []Result) types.Rating {
+	mu := (player.Rating - DefaultRating) / scale
+	phi := player.Deviation / scale
+	sigma := player.Volatility
+
+	if len(results) == 0 {
+		// Only the deviation grows when the player doesn't play
+		phi = math.Sqrt(phi*phi + sigma*sigma)
+		return types.Rating{Rating: player.Rating, Deviation: math.Min(phi*scale, DefaultDeviation), Volatility: sigma}
+	}
+
+	var variance, improvement float64
+	for _, result := range results {
+		muOpponent := (result.Opponent.Rating - DefaultRating) / scale
+		gOpponent := g(result.Opponent.Deviation / scale)
+		expected := expectedScore(mu, muOpponent, gOpponent)
+		variance += gOpponent * gOpponent * expected * (1 - expected)
+		improvement += gOpponent * (result.Score - expected)
+	}
+	variance = 1 / variance
+	delta := variance * improvement
+
+	sigma = newVolatility(delta, phi, variance, sigma)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	mu = mu + phi*phi*improvement
+
+	return types.Rating{
+		Rating:     mu*scale + DefaultRating,
+		Deviation:  math.Min(phi*scale, DefaultDeviation),
+		Volatility: sigma,
+	}
+}
+
+// UpdateGame returns the ratings of both players of a game after its result
+func UpdateGame(playerX types.Rating, playerO types.Rating, result types.GameResult) (types.Rating, types.Rating) {
+	scoreX := 0.5
+	switch result {
+	case types.ResultXWins:
+		scoreX = 1
+	case types.ResultOWins:
+		scoreX = 0
+	}
+	newX := Update(playerX, []Result{{Opponent: playerO, Score: scoreX}})
+	newO := Update(playerO, []Result{{Opponent: playerX, Score: 1 - scoreX}})
+	return newX, newO
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expectedScore(mu float64, muOpponent float64, gOpponent float64) float64 {
+	return 1 / (1 + math.Exp(-gOpponent*(mu-muOpponent)))
+}
+
+// newVolatility solves the volatility equation with the Illinois algorithm (step 5 of the paper)
+func newVolatility(delta float64, phi float64, variance float64, sigma float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+		return ex*(delta*delta-phi*phi-variance-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+variance {
+		B = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
@@ -0,0 +1,75 @@
+package ratings
+
+import (
+	"math"
+	"testing"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+func assertClose(t *testing.T, name string, got float64, want float64, tolerance float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+// The example of the Glicko-2 paper
+func TestUpdatePaperExample(t *testing.T) {
+	player := types.Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	results := []Result{
+		{Opponent: types.Rating{Rating: 1400, Deviation: 30, Volatility: 0.06}, Score: 1},
+		{Opponent: types.Rating{Rating: 1550, Deviation: 100, Volatility: 0.06}, Score: 0},
+		{Opponent: types.Rating{Rating: 1700, Deviation: 300, Volatility: 0.06}, Score: 0},
+	}
+
+	updated := Update(player, results)
+	assertClose(t, "rating", updated.Rating, 1464.05, 0.01)
+	assertClose(t, "deviation", updated.Deviation, 151.52, 0.01)
+	assertClose(t, "volatility", updated.Volatility, 0.059996, 0.000001)
+}
+
+func TestUpdateWithoutGame(t *testing.T) {
+	player := types.Rating{Rating: 1600, Deviation: 200, Volatility: 0.06}
+
+	updated := Update(player, nil)
+	assertClose(t, "rating", updated.Rating, 1600, 0)
+	assertClose(t, "deviation", updated.Deviation, math.Sqrt(200*200+0.06*0.06*scale*scale), 0.000001)
+
+	updated = Update(Default(), nil)
+	assertClose(t, "deviation", updated.Deviation, DefaultDeviation, 0)
+}
+
+func TestUpdateGame(t *testing.T) {
+	t.Run("win is symmetric", func(t *testing.T) {
+		x, o := UpdateGame(Default(), Default(), types.ResultXWins)
+		if x.Rating <= DefaultRating || o.Rating >= DefaultRating {
+			t.Fatalf("ratings = %v %v, want the winner above %v and the loser below", x.Rating, o.Rating, DefaultRating)
+		}
+		assertClose(t, "gains", x.Rating-DefaultRating, DefaultRating-o.Rating, 0.000001)
+		assertClose(t, "deviations", x.Deviation, o.Deviation, 0.000001)
+
+		oWins, xLoses := UpdateGame(Default(), Default(), types.ResultOWins)
+		assertClose(t, "winner as O", xLoses.Rating, x.Rating, 0.000001)
+		assertClose(t, "loser as X", oWins.Rating, o.Rating, 0.000001)
+	})
+
+	t.Run("draw between equals changes no rating", func(t *testing.T) {
+		x, o := UpdateGame(Default(), Default(), types.ResultDraw)
+		assertClose(t, "X rating", x.Rating, DefaultRating, 0.000001)
+		assertClose(t, "O rating", o.Rating, DefaultRating, 0.000001)
+		if x.Deviation >= DefaultDeviation {
+			t.Errorf("deviation = %v, want it below %v", x.Deviation, DefaultDeviation)
+		}
+	})
+
+	t.Run("draw moves the ratings closer", func(t *testing.T) {
+		strong := types.Rating{Rating: 1800, Deviation: 100, Volatility: 0.06}
+		weak := types.Rating{Rating: 1400, Deviation: 100, Volatility: 0.06}
+		x, o := UpdateGame(strong, weak, types.ResultDraw)
+		if x.Rating >= strong.Rating || o.Rating <= weak.Rating {
+			t.Errorf("ratings = %v %v, want the strong player to lose points and the weak one to gain", x.Rating, o.Rating)
+		}
+		assertClose(t, "changes", strong.Rating-x.Rating, o.Rating-weak.Rating, 0.000001)
+	})
+}
@@ -0,0 +1,156 @@
+package tournaments
+
+import (
+	"slices"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// Drawn games of a board are replayed with swapped colours at most maxReplays times, the best seed going through after
+const maxReplays = 2
+
+// SingleElimination plays a bracket whose size is the power of two above the number of players, the best seeds
+// having a bye in the first round. The winner of each board goes through to the next round
+type SingleElimination struct{}
+
+func (SingleElimination) Next(state State) []types.TournamentPairing {
+	if state.Round == 0 {
+		return firstBracketRound(state.Seeds)
+	}
+
+	var replays []types.TournamentPairing
+	var winners []string
+	for _, board := range boardsOfRound(state, state.Round) {
+		winner, replay := boardWinner(state.Seeds, board)
+		if replay != nil {
+			replays = append(replays, *replay)
+		} else {
+			winners = append(winners, winner)
+		}
+	}
+	if len(replays) > 0 {
+		return replays
+	}
+	if len(winners) <= 1 {
+		return nil
+	}
+
+	round := state.Round + 1
+	var pairings []types.TournamentPairing
+	for i := 0; i+1 < len(winners); i += 2 {
+		x, o := winners[i], winners[i+1]
+		if round%2 == 0 {
+			x, o = o, x
+		}
+		pairings = append(pairings, types.TournamentPairing{Round: round, Board: i/2 + 1, PlayerX: x, PlayerO: o})
+	}
+	return pairings
+}
+
+// firstBracketRound pairs the seeds so the best two can only meet in the final, and so on
+func firstBracketRound(seeds []string) []types.TournamentPairing {
+	size := 1
+	for size < len(seeds) {
+		size *= 2
+	}
+	// Seeds from 1 in the order of the bracket: 1 8 4 5 2 7 3 6 for 8 players
+	order := []int{1}
+	for len(order) < size {
+		var next []int
+		for _, seed := range order {
+			next = append(next, seed, 2*len(order)+1-seed)
+		}
+		order = next
+	}
+
+	var pairings []types.TournamentPairing
+	for i := 0; i+1 < len(order); i += 2 {
+		pairing := types.TournamentPairing{Round: 1, Board: i/2 + 1, PlayerX: seeds[order[i]-1]}
+		if order[i+1] <= len(seeds) {
+			pairing.PlayerO = seeds[order[i+1]-1]
+		}
+		pairings = append(pairings, pairing)
+	}
+	return pairings
+}
+
+// boardsOfRound returns the games of the round grouped by board, in the order of the boards
+func boardsOfRound(state State, round int) [][]types.TournamentGame {
+	var boards [][]types.TournamentGame
+	for _, game := range state.Games {
+		if game.Round != round {
+			continue
+		}
+		if len(boards) > 0 && boards[len(boards)-1][0].Board == game.Board {
+			boards[len(boards)-1] = append(boards[len(boards)-1], game)
+		} else {
+			boards = append(boards, []types.TournamentGame{game})
+		}
+	}
+	return boards
+}
+
+// boardWinner returns the player going through with the games of a board, or the replay to play after a draw
+func boardWinner(seeds []string, board []types.TournamentGame) (string, *types.TournamentPairing) {
+	last := board[len(board)-1]
+	if last.PlayerO == "" {
+		return last.PlayerX, nil
+	}
+	switch types.GameResult(last.Result) {
+	case types.ResultXWins:
+		return last.PlayerX, nil
+	case types.ResultOWins:
+		return last.PlayerO, nil
+	}
+
+	if last.Replay < maxReplays {
+		replay := last.TournamentPairing
+		replay.Replay++
+		replay.PlayerX, replay.PlayerO = last.PlayerO, last.PlayerX
+		return "", &replay
+	}
+	if slices.Index(seeds, last.PlayerX) < slices.Index(seeds, last.PlayerO) {
+		return last.PlayerX, nil
+	}
+	return last.PlayerO, nil
+}
+
+// Standings ranks the players still in the bracket first, then by the round they were eliminated at.
+// Players eliminated at the same round share the rank
+func (SingleElimination) Standings(state State) []types.TournamentStanding {
+	standings := scoreStandings(state)
+	// Last round reached, rounds after the elimination count for the players still in
+	reached := make(map[string]int)
+	eliminated := make(map[string]bool)
+	for round := 1; round <= state.Round; round++ {
+		for _, board := range boardsOfRound(state, round) {
+			for _, game := range board {
+				reached[game.PlayerX] = round
+				reached[game.PlayerO] = round
+			}
+			last := board[len(board)-1]
+			if last.GameId != 0 && last.Status != types.StatusTerminated {
+				continue
+			}
+			winner, replay := boardWinner(state.Seeds, board)
+			if replay == nil && last.PlayerO != "" {
+				loser := last.PlayerX
+				if winner == last.PlayerX {
+					loser = last.PlayerO
+				}
+				eliminated[loser] = true
+			}
+		}
+	}
+
+	for i := range standings {
+		standings[i].Eliminated = eliminated[standings[i].Name]
+	}
+	rank(standings, func(standing types.TournamentStanding) []float64 {
+		if standing.Eliminated {
+			return []float64{0, float64(reached[standing.Name])}
+		}
+		return []float64{1, 0}
+	})
+	return standings
+}
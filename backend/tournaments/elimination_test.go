@@ -0,0 +1,136 @@
+package tournaments
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// replayed returns the game as the replay of its board
+func replayed(game types.TournamentGame, replay int) types.TournamentGame {
+	game.Replay = replay
+	return game
+}
+
+func replayPairing(round int, board int, replay int, x string, o string) types.TournamentPairing {
+	return types.TournamentPairing{Round: round, Board: board, Replay: replay, PlayerX: x, PlayerO: o}
+}
+
+func TestSingleEliminationNext(t *testing.T) {
+	t.Parallel()
+	f := &fixture{}
+	tests := []struct {
+		name  string
+		state State
+		want  []types.TournamentPairing
+	}{
+		{
+			name:  "seeded bracket",
+			state: State{Seeds: []string{"s1", "s2", "s3", "s4", "s5", "s6", "s7", "s8"}},
+			want: []types.TournamentPairing{
+				pairing(1, 1, "s1", "s8"), pairing(1, 2, "s4", "s5"), pairing(1, 3, "s2", "s7"), pairing(1, 4, "s3", "s6"),
+			},
+		},
+		{
+			name:  "byes to the best seeds",
+			state: State{Seeds: []string{"a", "b", "c", "d", "e"}},
+			want:  []types.TournamentPairing{pairing(1, 1, "a", ""), pairing(1, 2, "d", "e"), pairing(1, 3, "b", ""), pairing(1, 4, "c", "")},
+		},
+		{
+			name: "players with a bye go through",
+			state: State{Seeds: []string{"a", "b", "c", "d", "e"}, Round: 1, Games: []types.TournamentGame{
+				bye(1, 1, "a"),
+				f.played(1, 2, "d", "e", types.ResultOWins),
+				bye(1, 3, "b"),
+				bye(1, 4, "c"),
+			}},
+			// Colours swap every round
+			want: []types.TournamentPairing{pairing(2, 1, "e", "a"), pairing(2, 2, "c", "b")},
+		},
+		{
+			name: "final",
+			state: State{Seeds: []string{"a", "b", "c", "d"}, Round: 2, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "d", types.ResultOWins),
+				f.played(1, 2, "b", "c", types.ResultXWins),
+				f.played(2, 1, "b", "d", types.ResultOWins),
+				f.played(2, 2, "c", "a", types.ResultXWins),
+			}},
+			want: []types.TournamentPairing{pairing(3, 1, "d", "c")},
+		},
+		{
+			name: "over after the final",
+			state: State{Seeds: []string{"a", "b"}, Round: 1, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "b", types.ResultOWins),
+			}},
+			want: nil,
+		},
+		{
+			name: "draw replayed with swapped colours",
+			state: State{Seeds: []string{"a", "b", "c", "d"}, Round: 1, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "d", types.ResultDraw),
+				f.played(1, 2, "b", "c", types.ResultXWins),
+			}},
+			want: []types.TournamentPairing{replayPairing(1, 1, 1, "d", "a")},
+		},
+		{
+			name: "second replay",
+			state: State{Seeds: []string{"a", "b", "c", "d"}, Round: 1, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "d", types.ResultDraw),
+				replayed(f.played(1, 1, "d", "a", types.ResultDraw), 1),
+				f.played(1, 2, "b", "c", types.ResultXWins),
+			}},
+			want: []types.TournamentPairing{replayPairing(1, 1, 2, "a", "d")},
+		},
+		{
+			name: "replay won",
+			state: State{Seeds: []string{"a", "b", "c", "d"}, Round: 1, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "d", types.ResultDraw),
+				replayed(f.played(1, 1, "d", "a", types.ResultXWins), 1),
+				f.played(1, 2, "b", "c", types.ResultXWins),
+			}},
+			want: []types.TournamentPairing{pairing(2, 1, "b", "d")},
+		},
+		{
+			name: "best seed through after the last replay",
+			state: State{Seeds: []string{"a", "b", "c", "d"}, Round: 1, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "d", types.ResultDraw),
+				replayed(f.played(1, 1, "d", "a", types.ResultDraw), 1),
+				replayed(f.played(1, 1, "a", "d", types.ResultDraw), 2),
+				f.played(1, 2, "b", "c", types.ResultXWins),
+			}},
+			want: []types.TournamentPairing{pairing(2, 1, "b", "a")},
+		},
+	}
+	for _, test := range tests {
+		if got := (SingleElimination{}).Next(test.state); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Next = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSingleEliminationStandings(t *testing.T) {
+	t.Parallel()
+	f := &fixture{}
+	state := State{Seeds: []string{"a", "b", "c", "d", "e"}, Round: 2, Games: []types.TournamentGame{
+		bye(1, 1, "a"),
+		f.played(1, 2, "d", "e", types.ResultOWins),
+		bye(1, 3, "b"),
+		bye(1, 4, "c"),
+		f.played(2, 1, "e", "a", types.ResultDraw),
+		replayed(f.played(2, 1, "a", "e", types.ResultDraw), 1),
+		replayed(f.played(2, 1, "e", "a", types.ResultDraw), 2),
+		f.played(2, 2, "c", "b", types.ResultXWins),
+	}}
+	var got []string
+	for _, standing := range (SingleElimination{}).Standings(state) {
+		got = append(got, standing.Name)
+		if standing.Eliminated != (standing.Name == "d" || standing.Name == "e" || standing.Name == "b") {
+			t.Errorf("%s eliminated: %v", standing.Name, standing.Eliminated)
+		}
+	}
+	// e and b went out in the second round, d in the first
+	if want := []string{"a", "c", "b", "e", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("standings %v, want %v", got, want)
+	}
+}
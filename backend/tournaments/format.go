@@ -0,0 +1,119 @@
+package tournaments
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+var ErrUnknownFormat = errors.New("unknown tournament format")
+
+// State is what formats know of a tournament to pair its players and rank them
+type State struct {
+	// Players by seed, the best first
+	Seeds []string
+	// Current round, 0 before the first one
+	Round int
+	// Games by round, board and replay
+	Games []types.TournamentGame
+}
+
+// Format pairs the players of a tournament round after round
+type Format interface {
+	// Next returns the pairings to play once every game of the state is over: the first round for a state without games,
+	// replays of the drawn games or the next round. No pairing means the tournament is over
+	Next(state State) []types.TournamentPairing
+	Standings(state State) []types.TournamentStanding
+}
+
+var formats = map[string]Format{
+	"round-robin":        RoundRobin{},
+	"single-elimination": SingleElimination{},
+	"swiss":              Swiss{},
+}
+
+func GetFormat(name string) (Format, error) {
+	format, ok := formats[name]
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+	return format, nil
+}
+
+// score returns the points of the player for a game over, false for a bye or a game they didn't play
+func score(game types.TournamentGame, player string) (float64, bool) {
+	if game.GameId == 0 || (player != game.PlayerX && player != game.PlayerO) {
+		return 0, false
+	}
+	switch types.GameResult(game.Result) {
+	case types.ResultDraw:
+		return 0.5, true
+	case types.ResultXWins:
+		if player == game.PlayerX {
+			return 1, true
+		}
+		return 0, true
+	case types.ResultOWins:
+		if player == game.PlayerO {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// scoreStandings returns the standings of the seeds, in their order, with the scores of the games over
+func scoreStandings(state State) []types.TournamentStanding {
+	standings := make([]types.TournamentStanding, len(state.Seeds))
+	for i, player := range state.Seeds {
+		standings[i].Name = player
+		for _, game := range state.Games {
+			points, ok := score(game, player)
+			if !ok {
+				continue
+			}
+			standings[i].Score += points
+			switch points {
+			case 1:
+				standings[i].Wins++
+			case 0.5:
+				standings[i].Draws++
+			default:
+				standings[i].Losses++
+			}
+		}
+	}
+	return standings
+}
+
+// rank sorts the standings, given in the order of the seeds, by their keys compared in order, the best being the highest.
+// Players with the same keys share the rank and stay in the order of the seeds
+func rank(standings []types.TournamentStanding, keys func(standing types.TournamentStanding) []float64) {
+	allKeys := make(map[string][]float64, len(standings))
+	for _, standing := range standings {
+		allKeys[standing.Name] = keys(standing)
+	}
+	compare := func(a, b []float64) int {
+		for i := range a {
+			if a[i] != b[i] {
+				if a[i] > b[i] {
+					return -1
+				}
+				return 1
+			}
+		}
+		return 0
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		return compare(allKeys[standings[i].Name], allKeys[standings[j].Name]) < 0
+	})
+	for i := range standings {
+		if i > 0 && compare(allKeys[standings[i].Name], allKeys[standings[i-1].Name]) == 0 {
+			standings[i].Rank = standings[i-1].Rank
+		} else {
+			standings[i].Rank = i + 1
+		}
+	}
+}
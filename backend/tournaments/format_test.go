@@ -0,0 +1,27 @@
+package tournaments
+
+import "github.com/allanlepinay/TicTacToe/backend/types"
+
+// fixture builds the games of a test, numbering them from 1 like the store
+type fixture struct {
+	lastGameId int64
+}
+
+// played returns a game over of the round, X getting the result
+func (f *fixture) played(round int, board int, x string, o string, result types.GameResult) types.TournamentGame {
+	f.lastGameId++
+	return types.TournamentGame{
+		TournamentPairing: types.TournamentPairing{Round: round, Board: board, PlayerX: x, PlayerO: o},
+		GameId:            f.lastGameId,
+		Status:            types.StatusTerminated,
+		Result:            int64(result),
+	}
+}
+
+func bye(round int, board int, player string) types.TournamentGame {
+	return types.TournamentGame{TournamentPairing: types.TournamentPairing{Round: round, Board: board, PlayerX: player}}
+}
+
+func pairing(round int, board int, x string, o string) types.TournamentPairing {
+	return types.TournamentPairing{Round: round, Board: board, PlayerX: x, PlayerO: o}
+}
@@ -0,0 +1,218 @@
+package tournaments
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/allanlepinay/TicTacToe/backend/database"
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+var (
+	ErrNotCreator       = errors.New("only the creator can start the tournament")
+	ErrNotEnoughPlayers = errors.New("a tournament needs at least 2 players")
+)
+
+type EventType int
+
+const (
+	EventGameReady = iota
+	EventFinished
+)
+
+// Event is emitted by the manager, Game is the game to play for EventGameReady, Players are the players to notify
+type Event struct {
+	Type         EventType
+	TournamentId int64
+	Game         types.TournamentGame
+	Players      []string
+}
+
+// Manager starts the rounds of the tournaments as their games end, it is safe for concurrent use
+type Manager struct {
+	store database.Store
+	// Serializes the creation of the games so a round isn't started twice when its last games end together
+	mutex  sync.Mutex
+	events chan Event
+}
+
+func NewManager(store database.Store) *Manager {
+	return &Manager{
+		store:  store,
+		events: make(chan Event, 100),
+	}
+}
+
+// Events returns the channel on which ready games and finished tournaments are emitted
+func (m *Manager) Events() <-chan Event {
+	return m.events
+}
+
+func (m *Manager) Create(name string, creatorName string, format string, variant string, size types.BoardSize, rated bool) (types.Tournament, error) {
+	if _, err := GetFormat(format); err != nil {
+		return types.Tournament{}, err
+	}
+	return m.store.CreateTournament(name, creatorName, format, variant, size, rated)
+}
+
+// Start closes the registration, seeds the players by rating and creates the games of the first round
+func (m *Manager) Start(tournamentId int64, username string) (types.Tournament, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	tournament, err := m.store.GetTournament(tournamentId)
+	if err != nil {
+		return types.Tournament{}, err
+	}
+	if tournament.Creator != username {
+		return types.Tournament{}, ErrNotCreator
+	}
+	if tournament.Status != types.TournamentRegistration {
+		return types.Tournament{}, database.ErrRegistrationClosed
+	}
+	if len(tournament.Players) < 2 {
+		return types.Tournament{}, ErrNotEnoughPlayers
+	}
+
+	seeds, err := m.seed(tournament.Players)
+	if err != nil {
+		return types.Tournament{}, err
+	}
+	tournament, err = m.store.StartTournament(tournamentId, seeds)
+	if err != nil {
+		return types.Tournament{}, err
+	}
+	if err = m.advance(tournament); err != nil {
+		return types.Tournament{}, err
+	}
+	return m.store.GetTournament(tournamentId)
+}
+
+// seed orders the players by rating, then by registration
+func (m *Manager) seed(players []string) ([]string, error) {
+	ratings := make(map[string]float64, len(players))
+	for _, name := range players {
+		player, err := m.store.GetPlayerByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tournament player: %w", err)
+		}
+		ratings[name] = player.Rating.Rating
+	}
+
+	seeds := append([]string{}, players...)
+	sort.SliceStable(seeds, func(i, j int) bool {
+		return ratings[seeds[i]] > ratings[seeds[j]]
+	})
+	return seeds, nil
+}
+
+// GameEnded starts the next games of the tournament of the game, if any, once its round is over
+func (m *Manager) GameEnded(gameId int64) error {
+	tournamentId, err := m.store.GetTournamentOfGame(gameId)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	tournament, err := m.store.GetTournament(tournamentId)
+	if err != nil {
+		return err
+	}
+	if tournament.Status != types.TournamentInProgress {
+		return nil
+	}
+	return m.advance(tournament)
+}
+
+// Resume starts the games the tournaments in progress are waiting for, in case the server stopped before creating them
+func (m *Manager) Resume() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	tournaments, err := m.store.ListTournaments()
+	if err != nil {
+		return err
+	}
+	for _, tournament := range tournaments {
+		if tournament.Status != types.TournamentInProgress {
+			continue
+		}
+		if err = m.advance(tournament); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advance creates the next games of the tournament when none is being played, until one has to be played.
+// It must be called with the mutex held
+func (m *Manager) advance(tournament types.Tournament) error {
+	format, err := GetFormat(tournament.Format)
+	if err != nil {
+		return err
+	}
+
+	for {
+		games, err := m.store.GetTournamentGames(tournament.ID)
+		if err != nil {
+			return err
+		}
+		for _, game := range games {
+			if game.GameId != 0 && game.Status != types.StatusTerminated {
+				return nil
+			}
+		}
+
+		pairings := format.Next(State{Seeds: tournament.Players, Round: tournament.Round, Games: games})
+		if len(pairings) == 0 {
+			if err = m.store.FinishTournament(tournament.ID); err != nil {
+				return err
+			}
+			m.events <- Event{Type: EventFinished, TournamentId: tournament.ID, Players: tournament.Players}
+			return nil
+		}
+
+		created, err := m.store.CreateTournamentGames(tournament.ID, pairings)
+		if err != nil {
+			return err
+		}
+		for _, game := range created {
+			tournament.Round = max(tournament.Round, game.Round)
+			if game.GameId != 0 {
+				m.events <- Event{Type: EventGameReady, TournamentId: tournament.ID, Game: game, Players: []string{game.PlayerX, game.PlayerO}}
+			}
+		}
+	}
+}
+
+// Details returns the tournament with its games and standings
+func (m *Manager) Details(tournamentId int64) (types.TournamentDetails, error) {
+	tournament, err := m.store.GetTournament(tournamentId)
+	if err != nil {
+		return types.TournamentDetails{}, err
+	}
+	format, err := GetFormat(tournament.Format)
+	if err != nil {
+		return types.TournamentDetails{}, err
+	}
+	games, err := m.store.GetTournamentGames(tournamentId)
+	if err != nil {
+		return types.TournamentDetails{}, err
+	}
+	if games == nil {
+		games = []types.TournamentGame{}
+	}
+
+	return types.TournamentDetails{
+		Tournament: tournament,
+		Games:      games,
+		Standings:  format.Standings(State{Seeds: tournament.Players, Round: tournament.Round, Games: games}),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package tournaments
+
+import "github.com/allanlepinay/TicTacToe/backend/types"
+
+// RoundRobin makes every player meet every other once, with the circle method.
+// With an odd number of players, one of them has a bye each round, on the last board, which doesn't score
+type RoundRobin struct{}
+
+func (RoundRobin) Next(state State) []types.TournamentPairing {
+	players := append([]string{}, state.Seeds...)
+	// The bye stays in place, so it is met between the two sides of the circle and the colours keep alternating
+	if len(players)%2 == 1 {
+		players = append([]string{""}, players...)
+	}
+	if state.Round >= len(players)-1 {
+		return nil
+	}
+	round := state.Round + 1
+
+	// The first player stays in place while the others rotate by one each round
+	rotated := []string{players[0]}
+	for i := 1; i < len(players); i++ {
+		rotated = append(rotated, players[1+(i-1+round-1)%(len(players)-1)])
+	}
+
+	var pairings []types.TournamentPairing
+	var byePairing *types.TournamentPairing
+	for i := 0; i < len(rotated)/2; i++ {
+		x, o := rotated[i], rotated[len(rotated)-1-i]
+		// The first player alternates colours every round. The others move by one board each round, switching
+		// side when they go round the circle, so alternating colours between boards alternates theirs too
+		if (i == 0 && round%2 == 0) || (i > 0 && i%2 == 0) {
+			x, o = o, x
+		}
+		if x == "" {
+			x, o = o, x
+		}
+		pairing := types.TournamentPairing{Round: round, PlayerX: x, PlayerO: o}
+		if o == "" {
+			byePairing = &pairing
+			continue
+		}
+		pairings = append(pairings, pairing)
+	}
+	if byePairing != nil {
+		pairings = append(pairings, *byePairing)
+	}
+	for i := range pairings {
+		pairings[i].Board = i + 1
+	}
+	return pairings
+}
+
+// Standings ranks by score then wins
+func (RoundRobin) Standings(state State) []types.TournamentStanding {
+	standings := scoreStandings(state)
+	rank(standings, func(standing types.TournamentStanding) []float64 {
+		return []float64{standing.Score, float64(standing.Wins)}
+	})
+	return standings
+}
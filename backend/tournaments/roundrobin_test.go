@@ -0,0 +1,122 @@
+package tournaments
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+func TestRoundRobinNext(t *testing.T) {
+	t.Parallel()
+	f := &fixture{}
+	tests := []struct {
+		name   string
+		seeds  []string
+		rounds [][]types.TournamentPairing
+	}{
+		{
+			name:  "four players",
+			seeds: []string{"a", "b", "c", "d"},
+			rounds: [][]types.TournamentPairing{
+				{pairing(1, 1, "a", "d"), pairing(1, 2, "b", "c")},
+				{pairing(2, 1, "b", "a"), pairing(2, 2, "c", "d")},
+				{pairing(3, 1, "a", "c"), pairing(3, 2, "d", "b")},
+			},
+		},
+		{
+			// The bye is on the last board and each player's colours alternate around it
+			name:  "three players, one bye each round",
+			seeds: []string{"a", "b", "c"},
+			rounds: [][]types.TournamentPairing{
+				{pairing(1, 1, "a", "b"), pairing(1, 2, "c", "")},
+				{pairing(2, 1, "b", "c"), pairing(2, 2, "a", "")},
+				{pairing(3, 1, "c", "a"), pairing(3, 2, "b", "")},
+			},
+		},
+	}
+	for _, test := range tests {
+		state := State{Seeds: test.seeds}
+		for _, want := range test.rounds {
+			got := RoundRobin{}.Next(state)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s: round %d = %v, want %v", test.name, state.Round+1, got, want)
+			}
+			state = f.playRound(state, got)
+		}
+		if got := (RoundRobin{}).Next(state); got != nil {
+			t.Errorf("%s: round after the last one = %v", test.name, got)
+		}
+	}
+}
+
+// playRound returns the state once the pairings are played, X winning
+func (f *fixture) playRound(state State, pairings []types.TournamentPairing) State {
+	for _, pairing := range pairings {
+		if pairing.PlayerO == "" {
+			state.Games = append(state.Games, bye(pairing.Round, pairing.Board, pairing.PlayerX))
+		} else {
+			state.Games = append(state.Games, f.played(pairing.Round, pairing.Board, pairing.PlayerX, pairing.PlayerO, types.ResultXWins))
+		}
+		state.Round = pairing.Round
+	}
+	return state
+}
+
+func TestRoundRobinTournaments(t *testing.T) {
+	t.Parallel()
+	for players := 2; players <= 10; players++ {
+		var seeds []string
+		for i := 0; i < players; i++ {
+			seeds = append(seeds, fmt.Sprintf("p%d", i+1))
+		}
+
+		f := &fixture{}
+		met := make(map[[2]string]int)
+		byes := make(map[string]int)
+		colours := make(map[string]int)
+		state := State{Seeds: seeds}
+		for pairings := (RoundRobin{}).Next(state); pairings != nil; pairings = (RoundRobin{}).Next(state) {
+			inRound := make(map[string]bool)
+			for _, p := range pairings {
+				for _, player := range []string{p.PlayerX, p.PlayerO} {
+					if player != "" && inRound[player] {
+						t.Fatalf("%d players: %s plays twice in round %d", players, player, p.Round)
+					}
+					inRound[player] = true
+				}
+				if p.PlayerO == "" {
+					byes[p.PlayerX]++
+					continue
+				}
+				met[[2]string{min(p.PlayerX, p.PlayerO), max(p.PlayerX, p.PlayerO)}]++
+				colours[p.PlayerX]++
+				colours[p.PlayerO]--
+			}
+			state = f.playRound(state, pairings)
+		}
+
+		wantRounds := players - 1 + players%2
+		if state.Round != wantRounds {
+			t.Errorf("%d players: %d rounds, want %d", players, state.Round, wantRounds)
+		}
+		if len(met) != players*(players-1)/2 {
+			t.Errorf("%d players: %d pairs met, want every one of the %d", players, len(met), players*(players-1)/2)
+		}
+		for pair, times := range met {
+			if times != 1 {
+				t.Errorf("%d players: %v met %d times", players, pair, times)
+			}
+		}
+		for _, player := range seeds {
+			if byes[player] != players%2 {
+				t.Errorf("%d players: %s has %d byes, want %d", players, player, byes[player], players%2)
+			}
+			// As many games with X as with O, one more of either with an odd number of games
+			if colours[player] < -1 || colours[player] > 1 {
+				t.Errorf("%d players: %s has %d more games with X than with O", players, player, colours[player])
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package tournaments
+
+import (
+	"sort"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+// Swiss pairs players of equal or close scores who haven't met yet, for as many rounds as an elimination bracket
+// of the same players. With an odd number of players, the lowest ranked player without a bye yet has one, scoring 1.
+// Drawn games aren't replayed
+type Swiss struct{}
+
+// Opponents tried by pairSwiss before giving up, the search being exponential when rematches can't be avoided
+const maxSwissAttempts = 10000
+
+// swissRecord is the history of a player used to pair them
+type swissRecord struct {
+	score     float64
+	opponents map[string]bool
+	hadBye    bool
+	// Games played as X minus games played as O
+	colourBalance int
+	lastColour    string
+}
+
+func swissRounds(players int) int {
+	rounds := 1
+	for 1<<rounds < players {
+		rounds++
+	}
+	return rounds
+}
+
+func swissRecords(state State) map[string]*swissRecord {
+	records := make(map[string]*swissRecord, len(state.Seeds))
+	for _, player := range state.Seeds {
+		records[player] = &swissRecord{opponents: make(map[string]bool)}
+	}
+	for _, game := range state.Games {
+		if game.PlayerO == "" {
+			records[game.PlayerX].score++
+			records[game.PlayerX].hadBye = true
+			continue
+		}
+		x, o := records[game.PlayerX], records[game.PlayerO]
+		x.opponents[game.PlayerO] = true
+		o.opponents[game.PlayerX] = true
+		x.colourBalance++
+		o.colourBalance--
+		x.lastColour, o.lastColour = "X", "O"
+		if points, ok := score(game, game.PlayerX); ok {
+			x.score += points
+			o.score += 1 - points
+		}
+	}
+	return records
+}
+
+func (Swiss) Next(state State) []types.TournamentPairing {
+	if state.Round >= swissRounds(len(state.Seeds)) {
+		return nil
+	}
+	round := state.Round + 1
+	records := swissRecords(state)
+
+	// By score, then by seed
+	ranking := append([]string{}, state.Seeds...)
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return records[ranking[i]].score > records[ranking[j]].score
+	})
+
+	bye := ""
+	if len(ranking)%2 == 1 {
+		byeIndex := len(ranking) - 1
+		for i := len(ranking) - 1; i >= 0; i-- {
+			if !records[ranking[i]].hadBye {
+				byeIndex = i
+				break
+			}
+		}
+		bye = ranking[byeIndex]
+		ranking = append(ranking[:byeIndex], ranking[byeIndex+1:]...)
+	}
+
+	// Rematches only when there is no other way to pair everyone, or none was found soon enough. Allowing them,
+	// the first opponent tried always leaves players who can be paired, so there is no backtracking
+	attempts := maxSwissAttempts
+	pairs := pairSwiss(ranking, records, false, &attempts)
+	if pairs == nil {
+		attempts = maxSwissAttempts
+		pairs = pairSwiss(ranking, records, true, &attempts)
+	}
+
+	var pairings []types.TournamentPairing
+	for i, pair := range pairs {
+		x, o := swissColours(pair[0], pair[1], records)
+		pairings = append(pairings, types.TournamentPairing{Round: round, Board: i + 1, PlayerX: x, PlayerO: o})
+	}
+	if bye != "" {
+		pairings = append(pairings, types.TournamentPairing{Round: round, Board: len(pairings) + 1, PlayerX: bye})
+	}
+	return pairings
+}
+
+// pairSwiss pairs the best ranked player with the one of the closest score they can play, preferring the ones
+// both can get their due colour with then the best ranked, backtracking when the others can't all be paired then.
+// It returns nil when there is no pairing or none was found trying attempts opponents
+func pairSwiss(ranking []string, records map[string]*swissRecord, allowRematches bool, attempts *int) [][2]string {
+	if len(ranking) == 0 {
+		return [][2]string{}
+	}
+	first := ranking[0]
+	candidates := make([]int, 0, len(ranking)-1)
+	for i := 1; i < len(ranking); i++ {
+		candidates = append(candidates, i)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := records[ranking[candidates[i]]], records[ranking[candidates[j]]]
+		distanceA, distanceB := records[first].score-a.score, records[first].score-b.score
+		if distanceA != distanceB {
+			return distanceA < distanceB
+		}
+		return colourCompatible(records[first], a) && !colourCompatible(records[first], b)
+	})
+
+	for _, i := range candidates {
+		opponent := ranking[i]
+		if !allowRematches && records[first].opponents[opponent] {
+			continue
+		}
+		if *attempts <= 0 {
+			return nil
+		}
+		*attempts--
+		rest := make([]string, 0, len(ranking)-2)
+		rest = append(rest, ranking[1:i]...)
+		rest = append(rest, ranking[i+1:]...)
+		if pairs := pairSwiss(rest, records, allowRematches, attempts); pairs != nil {
+			return append([][2]string{{first, opponent}}, pairs...)
+		}
+	}
+	return nil
+}
+
+// dueColour is the colour the player should play: the one they played less, otherwise the other than the last one.
+// It is empty before their first game
+func dueColour(record *swissRecord) string {
+	switch {
+	case record.colourBalance < 0:
+		return "X"
+	case record.colourBalance > 0:
+		return "O"
+	case record.lastColour == "X":
+		return "O"
+	case record.lastColour == "O":
+		return "X"
+	}
+	return ""
+}
+
+// colourCompatible tells if both players can get their due colour
+func colourCompatible(a *swissRecord, b *swissRecord) bool {
+	dueA, dueB := dueColour(a), dueColour(b)
+	return dueA == "" || dueB == "" || dueA != dueB
+}
+
+// swissColours gives both players their due colour when possible, otherwise the one who is owed it the most gets it,
+// then a, the best ranked
+func swissColours(a string, b string, records map[string]*swissRecord) (string, string) {
+	recordA, recordB := records[a], records[b]
+	dueA, dueB := dueColour(recordA), dueColour(recordB)
+	switch {
+	case dueA == "" && dueB == "":
+		return a, b
+	case dueA == "":
+		if dueB == "X" {
+			return b, a
+		}
+		return a, b
+	case dueB == "" || dueA != dueB:
+		if dueA == "X" {
+			return a, b
+		}
+		return b, a
+	}
+
+	// Both are owed the same colour
+	owedA, owedB := abs(recordA.colourBalance), abs(recordB.colourBalance)
+	if owedB > owedA {
+		a, b, dueA = b, a, dueB
+	}
+	if dueA == "X" {
+		return a, b
+	}
+	return b, a
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Standings ranks by score, then Buchholz, the sum of the scores of the opponents, then Sonneborn-Berger, the sum
+// of the scores of the opponents beaten and half the ones of the opponents drawn
+func (Swiss) Standings(state State) []types.TournamentStanding {
+	records := swissRecords(state)
+	standings := scoreStandings(state)
+	for i := range standings {
+		standing := &standings[i]
+		standing.Score = records[standing.Name].score
+		for _, game := range state.Games {
+			points, ok := score(game, standing.Name)
+			if !ok {
+				continue
+			}
+			opponent := game.PlayerX
+			if opponent == standing.Name {
+				opponent = game.PlayerO
+			}
+			standing.Buchholz += records[opponent].score
+			standing.SonnebornBerger += points * records[opponent].score
+		}
+	}
+
+	rank(standings, func(standing types.TournamentStanding) []float64 {
+		return []float64{standing.Score, standing.Buchholz, standing.SonnebornBerger}
+	})
+	return standings
+}
@@ -0,0 +1,325 @@
+package tournaments
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/allanlepinay/TicTacToe/backend/types"
+)
+
+func TestSwissNext(t *testing.T) {
+	t.Parallel()
+	f := &fixture{}
+	tests := []struct {
+		name  string
+		state State
+		want  []types.TournamentPairing
+	}{
+		{
+			name:  "first round by seed",
+			state: State{Seeds: []string{"a", "b", "c", "d"}},
+			want:  []types.TournamentPairing{pairing(1, 1, "a", "b"), pairing(1, 2, "c", "d")},
+		},
+		{
+			name: "winners meet with their due colours",
+			state: State{Seeds: []string{"a", "b", "c", "d"}, Round: 1, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "b", types.ResultXWins),
+				f.played(1, 2, "c", "d", types.ResultXWins),
+			}},
+			// a and c are both due O, a as the best ranked gets it
+			want: []types.TournamentPairing{pairing(2, 1, "c", "a"), pairing(2, 2, "b", "d")},
+		},
+		{
+			name: "no rematch while one is avoidable",
+			state: State{Seeds: []string{"a", "b", "c", "d"}, Round: 1, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "b", types.ResultDraw),
+				f.played(1, 2, "c", "d", types.ResultDraw),
+			}},
+			// Everyone is on the same score, a played b and prefers d who is due the other colour
+			want: []types.TournamentPairing{pairing(2, 1, "d", "a"), pairing(2, 2, "b", "c")},
+		},
+		{
+			name: "no rematch in the last round",
+			state: State{Seeds: []string{"a", "b", "c", "d", "e", "f"}, Round: 2, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "b", types.ResultXWins),
+				f.played(1, 2, "c", "d", types.ResultXWins),
+				f.played(1, 3, "e", "f", types.ResultXWins),
+				f.played(2, 1, "c", "a", types.ResultOWins),
+				f.played(2, 2, "b", "e", types.ResultXWins),
+				f.played(2, 3, "f", "d", types.ResultXWins),
+			}},
+			// Ranked a 2, b 1, c 1, e 1, f 1, d 0: a played b and c, then prefers f due O to e due X.
+			// b played e, d is owed X more than e
+			want: []types.TournamentPairing{pairing(3, 1, "a", "f"), pairing(3, 2, "b", "c"), pairing(3, 3, "d", "e")},
+		},
+		{
+			name:  "first bye to the lowest seed",
+			state: State{Seeds: []string{"a", "b", "c", "d", "e"}},
+			want:  []types.TournamentPairing{pairing(1, 1, "a", "b"), pairing(1, 2, "c", "d"), pairing(1, 3, "e", "")},
+		},
+		{
+			name: "bye to the lowest ranked without one",
+			state: State{Seeds: []string{"a", "b", "c", "d", "e"}, Round: 2, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "b", types.ResultXWins),
+				f.played(1, 2, "c", "d", types.ResultXWins),
+				bye(1, 3, "e"),
+				f.played(2, 1, "c", "a", types.ResultXWins),
+				f.played(2, 2, "b", "e", types.ResultXWins),
+				bye(2, 3, "d"),
+			}},
+			// Ranked c, a, b, d, e: e and d had a bye already. c played a and d, d is owed X more than a
+			want: []types.TournamentPairing{pairing(3, 1, "e", "c"), pairing(3, 2, "d", "a"), pairing(3, 3, "b", "")},
+		},
+		{
+			name: "over after the rounds of the bracket",
+			state: State{Seeds: []string{"a", "b", "c"}, Round: 2, Games: []types.TournamentGame{
+				f.played(1, 1, "a", "b", types.ResultDraw),
+				bye(1, 2, "c"),
+				f.played(2, 1, "c", "a", types.ResultDraw),
+				bye(2, 2, "b"),
+			}},
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Swiss{}.Next(test.state)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("Next = %v, want %v", got, test.want)
+			}
+			// Maps only index the records, the pairings must not change from a call to the next
+			for i := 0; i < 20; i++ {
+				if again := (Swiss{}).Next(test.state); !reflect.DeepEqual(again, got) {
+					t.Fatalf("Next = %v then %v", got, again)
+				}
+			}
+		})
+	}
+}
+
+func TestDueColour(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		record swissRecord
+		want   string
+	}{
+		{swissRecord{}, ""},
+		{swissRecord{colourBalance: -1, lastColour: "O"}, "X"},
+		{swissRecord{colourBalance: 1, lastColour: "X"}, "O"},
+		{swissRecord{colourBalance: 2, lastColour: "O"}, "O"},
+		{swissRecord{colourBalance: 0, lastColour: "X"}, "O"},
+		{swissRecord{colourBalance: 0, lastColour: "O"}, "X"},
+	}
+	for _, test := range tests {
+		if got := dueColour(&test.record); got != test.want {
+			t.Errorf("dueColour(%+v) = %q, want %q", test.record, got, test.want)
+		}
+	}
+}
+
+func TestSwissColours(t *testing.T) {
+	t.Parallel()
+	fresh := swissRecord{}
+	dueX := swissRecord{colourBalance: -1, lastColour: "O"}
+	dueO := swissRecord{colourBalance: 1, lastColour: "X"}
+	owedO := swissRecord{colourBalance: 2, lastColour: "X"}
+	tests := []struct {
+		name  string
+		a, b  swissRecord
+		wantX string
+		wantO string
+	}{
+		{"both fresh, a plays X", fresh, fresh, "a", "b"},
+		{"only b has a due colour", fresh, dueX, "b", "a"},
+		{"only a has a due colour", dueO, fresh, "b", "a"},
+		{"different due colours", dueX, dueO, "a", "b"},
+		{"different due colours swapped", dueO, dueX, "b", "a"},
+		{"same due colour, a gets it", dueO, dueO, "b", "a"},
+		{"same due colour, b owed more", dueO, owedO, "a", "b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := test.a, test.b
+			records := map[string]*swissRecord{"a": &a, "b": &b}
+			x, o := swissColours("a", "b", records)
+			if x != test.wantX || o != test.wantO {
+				t.Errorf("swissColours = %s, %s, want %s, %s", x, o, test.wantX, test.wantO)
+			}
+		})
+	}
+}
+
+func TestSwissStandings(t *testing.T) {
+	t.Parallel()
+	f := &fixture{}
+	state := State{Seeds: []string{"a", "b", "c", "d"}, Round: 2, Games: []types.TournamentGame{
+		f.played(1, 1, "a", "b", types.ResultXWins),
+		f.played(1, 2, "c", "d", types.ResultDraw),
+		f.played(2, 1, "c", "a", types.ResultDraw),
+		f.played(2, 2, "b", "d", types.ResultOWins),
+	}}
+	// Scores a 1.5, b 0, c 1, d 1.5
+	// Buchholz a 0+1, b 1.5+1.5, c 1.5+1.5, d 1+0
+	// Sonneborn-Berger a 0+1/2, b 0, c 1.5/2+1.5/2, d 1/2+0
+	want := []types.TournamentStanding{
+		{Rank: 1, Name: "a", Score: 1.5, Wins: 1, Draws: 1, Buchholz: 1, SonnebornBerger: 0.5},
+		{Rank: 1, Name: "d", Score: 1.5, Wins: 1, Draws: 1, Buchholz: 1, SonnebornBerger: 0.5},
+		{Rank: 3, Name: "c", Score: 1, Draws: 2, Buchholz: 3, SonnebornBerger: 1.5},
+		{Rank: 4, Name: "b", Score: 0, Losses: 2, Buchholz: 3, SonnebornBerger: 0},
+	}
+
+	got := Swiss{}.Standings(state)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Standings =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestSwissStandingsCountByes(t *testing.T) {
+	t.Parallel()
+	f := &fixture{}
+	state := State{Seeds: []string{"a", "b", "c"}, Round: 1, Games: []types.TournamentGame{
+		f.played(1, 1, "a", "b", types.ResultOWins),
+		bye(1, 2, "c"),
+	}}
+	got := Swiss{}.Standings(state)
+	// The bye scores but has no opponent for the tiebreaks
+	want := []types.TournamentStanding{
+		{Rank: 1, Name: "b", Score: 1, Wins: 1, Buchholz: 0, SonnebornBerger: 0},
+		{Rank: 1, Name: "c", Score: 1},
+		{Rank: 3, Name: "a", Score: 0, Losses: 1, Buchholz: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Standings =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// TestSwissTournaments plays whole tournaments, the better seed winning unless the board and round say otherwise
+func TestSwissTournaments(t *testing.T) {
+	t.Parallel()
+	for _, players := range []int{2, 3, 4, 5, 6, 7, 8, 9, 16, 17} {
+		t.Run(fmt.Sprintf("%d players", players), func(t *testing.T) {
+			t.Parallel()
+			f := &fixture{}
+			var seeds []string
+			for i := 0; i < players; i++ {
+				seeds = append(seeds, fmt.Sprintf("p%02d", i))
+			}
+			state := State{Seeds: seeds}
+			met := make(map[[2]string]bool)
+			byes := make(map[string]int)
+			colours := make(map[string]string)
+
+			for {
+				pairings := Swiss{}.Next(state)
+				if pairings == nil {
+					break
+				}
+				state.Round++
+				seen := make(map[string]bool)
+				for _, p := range pairings {
+					if p.Round != state.Round {
+						t.Fatalf("pairing %+v in round %d", p, state.Round)
+					}
+					for _, player := range []string{p.PlayerX, p.PlayerO} {
+						if player == "" {
+							continue
+						}
+						if seen[player] {
+							t.Fatalf("round %d: %s paired twice", state.Round, player)
+						}
+						seen[player] = true
+					}
+					if p.PlayerO == "" {
+						byes[p.PlayerX]++
+						state.Games = append(state.Games, bye(p.Round, p.Board, p.PlayerX))
+						continue
+					}
+					if met[[2]string{p.PlayerX, p.PlayerO}] || met[[2]string{p.PlayerO, p.PlayerX}] {
+						t.Fatalf("round %d: rematch of %s and %s", state.Round, p.PlayerX, p.PlayerO)
+					}
+					met[[2]string{p.PlayerX, p.PlayerO}] = true
+					colours[p.PlayerX] += "X"
+					colours[p.PlayerO] += "O"
+
+					var result types.GameResult = types.ResultXWins
+					if p.PlayerO < p.PlayerX {
+						result = types.ResultOWins
+					}
+					if (p.Round+p.Board)%3 == 0 {
+						result = types.ResultDraw
+					}
+					state.Games = append(state.Games, f.played(p.Round, p.Board, p.PlayerX, p.PlayerO, result))
+				}
+				if len(seen) != players {
+					t.Fatalf("round %d: %d players paired, want %d", state.Round, len(seen), players)
+				}
+			}
+
+			if state.Round != swissRounds(players) {
+				t.Errorf("%d rounds played, want %d", state.Round, swissRounds(players))
+			}
+			for player, count := range byes {
+				if count > 1 {
+					t.Errorf("%s had %d byes", player, count)
+				}
+			}
+			for player, played := range colours {
+				balance := 0
+				for _, colour := range played {
+					if colour == 'X' {
+						balance++
+					} else {
+						balance--
+					}
+				}
+				if balance > 2 || balance < -2 {
+					t.Errorf("%s played %s", player, played)
+				}
+			}
+		})
+	}
+}
+
+// With players who can't all be paired without rematches, the search gives up on avoiding them after maxSwissAttempts
+// instead of trying every pairing
+func TestSwissNextGivesUpAvoidingRematches(t *testing.T) {
+	t.Parallel()
+	f := &fixture{}
+	// Two groups of an odd number of players, each having played every player of the other group
+	var groupA, groupB []string
+	for i := 0; i < 19; i++ {
+		groupA = append(groupA, fmt.Sprintf("a%02d", i))
+	}
+	for i := 0; i < 21; i++ {
+		groupB = append(groupB, fmt.Sprintf("b%02d", i))
+	}
+	state := State{Seeds: append(append([]string{}, groupA...), groupB...), Round: 1}
+	for _, a := range groupA {
+		for _, b := range groupB {
+			state.Games = append(state.Games, f.played(1, len(state.Games)+1, a, b, types.ResultDraw))
+		}
+	}
+
+	pairings := Swiss{}.Next(state)
+	if len(pairings) != 20 {
+		t.Fatalf("%d pairings, want 20", len(pairings))
+	}
+	seen := make(map[string]bool)
+	rematches := 0
+	for _, p := range pairings {
+		for _, player := range []string{p.PlayerX, p.PlayerO} {
+			if seen[player] {
+				t.Fatalf("%s paired twice", player)
+			}
+			seen[player] = true
+		}
+		if (p.PlayerX[0] == 'a') != (p.PlayerO[0] == 'a') {
+			rematches++
+		}
+	}
+	if rematches == 0 {
+		t.Error("every player paired without rematch, which is impossible")
+	}
+}
@@ -1,19 +1,177 @@
 package types
 
+import "time"
+
 type Game struct {
-	ID        int64        `json:"id"`
-	Board     [3][3]string `json:"board"`
-	Turn      string       `json:"turn"`
-	Status    int64        `json:"status"`
-	PlayerXId int64        `json:"player_x_id"`
-	PlayerOId int64        `json:"player_o_id"`
+	ID          int64      `json:"id"`
+	Variant     string     `json:"variant"`
+	Board       [][]string `json:"board"`
+	Turn        string     `json:"turn"`
+	Status      int64      `json:"status"`
+	PlayerXId   int64      `json:"player_x_id"`
+	PlayerOId   int64      `json:"player_o_id"`
+	Result      int64      `json:"result"`
+	WinningLine [][2]int   `json:"winning_line"`
+	// Rated games update the ratings of the players, casual ones don't
+	Rated bool `json:"rated"`
+	BoardSize
+	// Ultimate only: winner of each sub-board ("D" when drawn) and the sub-board to play in, nil for a free move
+	MetaBoard   [][]string `json:"meta_board,omitempty"`
+	ActiveBoard *[2]int    `json:"active_board,omitempty"`
+}
+
+// GameDetails is a game with the information needed to display it without a websocket
+type GameDetails struct {
+	Game
+	PlayerXName string    `json:"player_x_name"`
+	PlayerOName string    `json:"player_o_name"`
+	MoveCount   int64     `json:"move_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// GameFilter selects the games to list, the zero value of a field doesn't filter
+type GameFilter struct {
+	Status *int64
+	// Name of one of the players
+	Player  string
+	Variant string
+	// Creation date range, From included and To excluded
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// GameList is a page of games, most recent first
+type GameList struct {
+	Games    []GameDetails `json:"games"`
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+// LeaderboardFilter selects the rated games finished since Since, all of them if zero, of Variant if not empty.
+// Player is the name of the player whose own rank is looked up
+type LeaderboardFilter struct {
+	Variant string
+	Since   time.Time
+	Player  string
+	Limit   int
+	Offset  int
+}
+
+// LeaderboardEntry is the standing of a player, a win scoring 1 and a draw 0.5.
+// Ties on score are broken by rating, players still tied share the rank
+type LeaderboardEntry struct {
+	Rank   int64   `json:"rank"`
+	Name   string  `json:"name"`
+	Rating float64 `json:"rating"`
+	Score  float64 `json:"score"`
+	Wins   int64   `json:"wins"`
+	Losses int64   `json:"losses"`
+	Draws  int64   `json:"draws"`
+}
+
+// Leaderboard is a page of the standings, Me is the standing of the requesting player, nil if they have no finished game
+type Leaderboard struct {
+	Entries  []LeaderboardEntry `json:"entries"`
+	Me       *LeaderboardEntry  `json:"me"`
+	Total    int64              `json:"total"`
+	Period   string             `json:"period"`
+	Variant  string             `json:"variant"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
+
+// BoardSize describes a m,n,k-game: a Width x Height board where WinLength marks in a row win
+type BoardSize struct {
+	Width     int `json:"width"`
+	Height    int `json:"height"`
+	WinLength int `json:"win_length"`
+}
+
+// Placement is a move as the variants see it: the cell played and Extra, empty unless the variant needs more
+// to describe the move, like a chosen mark or a layer. Only the variant interprets Extra
+type Placement struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Extra string `json:"extra,omitempty"`
 }
 
 type Move struct {
 	WebsocketMessage
-	X    int    `json:"x"`
-	Y    int    `json:"y"`
+	Placement
 	Turn string `json:"turn"`
+	// Variant requested by JoinQueue, the default one if empty
+	Variant string `json:"variant"`
+	// Requested by CreatePrivateGame and Challenge, queue games are always rated
+	Rated bool `json:"rated"`
+	// Accept by JoinQueue to be paired with the last opponent again
+	Rematch bool `json:"rematch"`
+	// Board requested by JoinQueue, StartBotGame, CreatePrivateGame and Challenge, the one of the variant if zero
+	Size BoardSize `json:"size"`
+}
+
+// PlayedMove is a move of the history of a game, Ply starting at 1
+type PlayedMove struct {
+	Ply    int64  `json:"ply"`
+	Player string `json:"player"`
+	Placement
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReplayStep is the game after Ply moves, Move being the last of them
+type ReplayStep struct {
+	Ply  int64       `json:"ply"`
+	Move *PlayedMove `json:"move,omitempty"`
+	Game Game        `json:"game"`
+}
+
+const (
+	EvaluationWin  = "win"
+	EvaluationDraw = "draw"
+	EvaluationLoss = "loss"
+)
+
+// Evaluation is the theoretical result of a position for the player to move, Distance moves away with best play
+type Evaluation struct {
+	Result   string `json:"result"`
+	Distance int    `json:"distance"`
+}
+
+// MoveEvaluation is the evaluation of a move for the player making it
+type MoveEvaluation struct {
+	X          int        `json:"x"`
+	Y          int        `json:"y"`
+	Evaluation Evaluation `json:"evaluation"`
+}
+
+// PositionAnalysis is the analysis of the position after Ply moves and of the move played from it, if any
+type PositionAnalysis struct {
+	Ply        int64            `json:"ply"`
+	Board      [][]string       `json:"board"`
+	Turn       string           `json:"turn"`
+	Evaluation Evaluation       `json:"evaluation"`
+	BestMoves  []MoveEvaluation `json:"best_moves"`
+	PlayedMove *MoveEvaluation  `json:"played_move,omitempty"`
+	// The played move turned a win into a draw or loss, or a draw into a loss
+	Blunder bool `json:"blunder"`
+}
+
+// QueueStatus is sent to the players waiting for an opponent
+type QueueStatus struct {
+	// Among the players waiting for the same variant, from 1
+	Position int `json:"position"`
+	// In seconds, -1 when there is no recent match to estimate it from
+	EstimatedWait float64 `json:"estimated_wait"`
+}
+
+// SpectatorCount is sent to a game when a spectator comes or leaves
+type SpectatorCount struct {
+	Type   string `json:"type"`
+	GameId int64  `json:"gameId"`
+	Count  int    `json:"count"`
 }
 
 type WebsocketMessage struct {
@@ -26,7 +184,23 @@ type WebsocketMessage struct {
 type PlayerProfile struct {
 	Type string `json:"type"`
 	Player
-	Games []Game `json:"games"`
+	WinRate float64 `json:"win_rate"`
+	Streak  Streak  `json:"streak"`
+	Games   []Game  `json:"games"`
+	// From the oldest rated game
+	RatingHistory []RatingChange `json:"rating_history"`
+}
+
+const (
+	StreakWin  = "win"
+	StreakLoss = "loss"
+	StreakDraw = "draw"
+)
+
+// Streak is the number of consecutive games with the same result, from the most recent game
+type Streak struct {
+	Result string `json:"result"`
+	Count  int64  `json:"count"`
 }
 
 type Player struct {
@@ -36,9 +210,24 @@ type Player struct {
 	Wins          int64  `json:"wins"`
 	Loses         int64  `json:"loses"`
 	Draw          int64  `json:"draw"`
+	Rating        Rating `json:"rating"`
 	WebsocketConn string `json:"websocket_conn"`
 }
 
+// Rating is a Glicko-2 rating, on the Glicko scale
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// RatingChange is the rating of a player after a rated game
+type RatingChange struct {
+	GameId int64 `json:"game_id"`
+	Rating
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
@@ -57,3 +246,124 @@ var StatusName = map[GameStatus]string{
 	StatusInProgress: "In-Progress",
 	StatusTerminated: "Terminated",
 }
+
+type GameResult int
+
+const (
+	ResultOngoing = iota
+	ResultXWins
+	ResultOWins
+	ResultDraw
+)
+
+var ResultName = map[GameResult]string{
+	ResultOngoing: "Ongoing",
+	ResultXWins:   "X-Wins",
+	ResultOWins:   "O-Wins",
+	ResultDraw:    "Draw",
+}
+
+type InvitationStatus int
+
+const (
+	InvitationPending = iota
+	InvitationAccepted
+	InvitationDeclined
+	InvitationExpired
+)
+
+var InvitationStatusName = map[InvitationStatus]string{
+	InvitationPending:  "Pending",
+	InvitationAccepted: "Accepted",
+	InvitationDeclined: "Declined",
+	InvitationExpired:  "Expired",
+}
+
+// Invitation is a private game waiting for its second player: anyone with the code
+// if ToName is empty, otherwise a challenge only ToName can accept. FromName plays X
+type Invitation struct {
+	Code     string `json:"code"`
+	FromName string `json:"from_name"`
+	ToName   string `json:"to_name,omitempty"`
+	Variant  string `json:"variant"`
+	BoardSize
+	Rated     bool      `json:"rated"`
+	Status    int64     `json:"status"`
+	GameId    int64     `json:"game_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type TournamentStatus int
+
+const (
+	TournamentRegistration = iota
+	TournamentInProgress
+	TournamentFinished
+)
+
+var TournamentStatusName = map[TournamentStatus]string{
+	TournamentRegistration: "Registration",
+	TournamentInProgress:   "In-Progress",
+	TournamentFinished:     "Finished",
+}
+
+// Tournament is played in rounds by its players, listed by seed once started and by registration before
+type Tournament struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Format  string `json:"format"`
+	Variant string `json:"variant"`
+	BoardSize
+	Rated   bool   `json:"rated"`
+	Status  int64  `json:"status"`
+	Creator string `json:"creator"`
+	// Current round, from 1, 0 during the registration
+	Round     int       `json:"round"`
+	Players   []string  `json:"players"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TournamentPairing is a game to play on a board of a round, PlayerO is empty for a bye.
+// Replay counts the games already played on the board, the drawn ones being replayed
+type TournamentPairing struct {
+	Round   int    `json:"round"`
+	Board   int    `json:"board"`
+	Replay  int    `json:"replay"`
+	PlayerX string `json:"player_x"`
+	PlayerO string `json:"player_o"`
+}
+
+// TournamentGame is a pairing with its game, whose status and result are copied. A bye has no game
+type TournamentGame struct {
+	TournamentPairing
+	TournamentId int64 `json:"tournament_id"`
+	GameId       int64 `json:"game_id"`
+	Status       int64 `json:"status"`
+	Result       int64 `json:"result"`
+}
+
+// TournamentStanding is the score of a player, a win counting 1 and a draw 0.5. Players can share a rank
+type TournamentStanding struct {
+	Rank       int     `json:"rank"`
+	Name       string  `json:"name"`
+	Score      float64 `json:"score"`
+	Wins       int     `json:"wins"`
+	Draws      int     `json:"draws"`
+	Losses     int     `json:"losses"`
+	Eliminated bool    `json:"eliminated"`
+	// Tiebreaks of Swiss tournaments
+	Buchholz        float64 `json:"buchholz"`
+	SonnebornBerger float64 `json:"sonneborn_berger"`
+}
+
+type TournamentDetails struct {
+	Tournament
+	Games     []TournamentGame     `json:"games"`
+	Standings []TournamentStanding `json:"standings"`
+}
+
+type Outcome struct {
+	Result      GameResult `json:"result"`
+	WinningLine [][2]int   `json:"winning_line"`
+}
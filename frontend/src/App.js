@@ -7,6 +7,11 @@ import RegisterPage from './pages/RegisterPage';
 import GamePage from './pages/GamePage';
 import LobbyPage from './pages/LobbyPage';
 import PlayerPage from './pages/PlayerPage';
+import ReplayPage from './pages/ReplayPage';
+import SpectatePage from './pages/SpectatePage';
+import LeaderboardPage from './pages/LeaderboardPage';
+import TournamentsPage from './pages/TournamentsPage';
+import TournamentPage from './pages/TournamentPage';
 
 function App() {
     const [auth, setAuth] = useState(null);
@@ -33,6 +38,11 @@ function App() {
                 <Route path="/game/:id" element={auth ? <GamePage /> : <LoginPage />} />
                 <Route path="/lobby" element={auth ? <LobbyPage /> : <LoginPage />} />
                 <Route path="/player/:id" element={auth ? <PlayerPage /> : <LoginPage />} />
+                <Route path="/replay/:id" element={auth ? <ReplayPage /> : <LoginPage />} />
+                <Route path="/spectate/:id" element={auth ? <SpectatePage /> : <LoginPage />} />
+                <Route path="/leaderboard" element={auth ? <LeaderboardPage /> : <LoginPage />} />
+                <Route path="/tournaments" element={auth ? <TournamentsPage /> : <LoginPage />} />
+                <Route path="/tournament/:id" element={auth ? <TournamentPage /> : <LoginPage />} />
                 <Route path="/leave-queue" element={auth ? "" : <LoginPage />} />
             </Routes>
         </Router>
@@ -1,19 +1,32 @@
 import React from 'react';
 
-function Board({ board, onClick }) {
+// subBoards and activeBoard are only set for Ultimate tic-tac-toe, activeBoard null meaning any open sub-board
+function Board({ board, winningLine = [], subBoards = false, activeBoard = null, onClick }) {
+    const isWinningCell = (i, j) => winningLine.some(([x, y]) => x === i && y === j);
+    // Shrink the cells so big boards (15x15 Gomoku) still fit on screen
+    const size = Math.max(board.length, board[0] ? board[0].length : 0);
+    const cellSize = Math.min(10, 80 / size);
+    const isActiveCell = (i, j) => subBoards && activeBoard !== null
+        && Math.floor(i / 3) === activeBoard[0] && Math.floor(j / 3) === activeBoard[1];
+    // Extra space between the sub-boards of Ultimate
+    const gap = (k) => (subBoards && k % 3 === 2 ? cellSize / 2 : 0);
+
     return (
         <div>
             {board.map((row, i) => (
-                <div key={i} style={{ display: 'flex' }}>
+                <div key={i} style={{ display: 'flex', marginBottom: `${gap(i)}vh` }}>
                     {row.map((cell, j) => (
                         <button
                             key={j}
                             onClick={() => onClick(i, j)}
                             style={{
-                                width: '10vh',
-                                height: '10vh',
-                                fontSize: '5vh',
-                                margin: '1vh'
+                                width: `${cellSize}vh`,
+                                height: `${cellSize}vh`,
+                                fontSize: `${cellSize / 2}vh`,
+                                margin: `${cellSize / 10}vh`,
+                                marginRight: `${cellSize / 10 + gap(j)}vh`,
+                                padding: 0,
+                                backgroundColor: isWinningCell(i, j) ? 'lightgreen' : (isActiveCell(i, j) ? 'lightyellow' : undefined)
                             }}
                         >
                             {cell}
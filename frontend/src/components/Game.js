@@ -2,12 +2,18 @@ import React, { useState, useEffect } from 'react';
 import Board from './Board';
 import { useSelector } from 'react-redux';
 
-function Game() {
+// Spectators receive the moves of the game but can't play
+function Game({ spectate = false }) {
     const [board, setBoard] = useState([['', '', ''], ['', '', ''], ['', '', '']]);
     const [turn, setTurn] = useState('X');
     const [gameOver, setGameOver] = useState(false);
     const [winner, setWinner] = useState('');
+    const [winningLine, setWinningLine] = useState([]);
+    const [variant, setVariant] = useState('classic');
+    const [activeBoard, setActiveBoard] = useState(null);
     const [wsStatus, setWsStatus] = useState('Disconnected');
+    const [error, setError] = useState('');
+    const [spectators, setSpectators] = useState(0);
     const socket = useSelector((state) => state.websocket.connection);
     const [gameId, setGameId] = useState(window.location.pathname.split('/').pop());
 
@@ -16,8 +22,8 @@ function Game() {
           setWsStatus('Connected');
           const username = localStorage.getItem('username');
           socket.send(JSON.stringify({
-            type: "JoinGame",
-            message: "JoinGame",
+            type: spectate ? "Spectate" : "JoinGame",
+            message: "",
             gameId: parseInt(gameId),
             username: username
           }));
@@ -37,27 +43,47 @@ function Game() {
             setWsStatus('Disconnected');
           };
         }
-      }, [socket, gameId]);
+
+        return () => {
+          if (spectate && socket && socket.readyState === WebSocket.OPEN) {
+            socket.send(JSON.stringify({ type: "StopSpectating", gameId: parseInt(gameId) }));
+          }
+        };
+      }, [socket, gameId, spectate]);
 
     const handleWebSocketMessage = (data) => {
         switch (data.type) {
             case 'move':
                 var game = JSON.parse(data.message);
+                setError('');
                 setBoard(game['board']);
+                setVariant(game['variant']);
+                setActiveBoard(game['active_board'] || null);
                 if (game['status'] == 2) { // Status Terminated
                     setGameOver(true);
-                    setWinner(game['turn'])
+                    setWinningLine(game['winning_line'] || []);
+                    switch (game['result']) {
+                        case 1: setWinner('X'); break; // Result X-Wins
+                        case 2: setWinner('O'); break; // Result O-Wins
+                        default: setWinner(''); // Result Draw
+                    }
                 } else {
                     setTurn(game['turn']);
                 }
                 break;
+            case 'error':
+                setError(data.message);
+                break;
+            case 'spectators':
+                setSpectators(data.count);
+                break;
             default:
                 console.log('Unknown message type:', data.type);
         }
     };
 
     const handleClick = (i, j) => {
-        if (board[i][j] !== '' || gameOver) return;
+        if (spectate || board[i][j] !== '' || gameOver) return;
 
         const move = { 
             type: 'move',
@@ -76,9 +102,17 @@ function Game() {
 
     return (
         <div>
-            <Board board={board} onClick={handleClick} />
+            <Board
+                board={board}
+                winningLine={winningLine}
+                subBoards={variant === 'ultimate'}
+                activeBoard={activeBoard}
+                onClick={handleClick}
+            />
             <div>Current Turn: {turn}</div>
-            {gameOver && <div>{winner} has won!</div>}
+            <div>Spectators: {spectators}</div>
+            {error && <div>{error}</div>}
+            {gameOver && (winner ? <div>{winner} has won!</div> : <div>Draw!</div>)}
             <div>WebSocket Status: {wsStatus}</div>
         </div>
     );
@@ -0,0 +1,70 @@
+import React, { useEffect, useState } from 'react';
+import '../axiosConfig';
+import axios from 'axios';
+
+const LeaderboardPage = () => {
+    const [leaderboard, setLeaderboard] = useState(null);
+    const [period, setPeriod] = useState('all-time');
+    const [variant, setVariant] = useState('');
+    const [page, setPage] = useState(1);
+    const [error, setError] = useState('');
+
+    useEffect(() => {
+        axios.get('/leaderboard', { params: { period: period, variant: variant || undefined, page: page } })
+            .then(response => {
+                setLeaderboard(response.data);
+                setError('');
+            })
+            .catch(error => setError('Failed to load the leaderboard'));
+    }, [period, variant, page]);
+
+    const lastPage = leaderboard ? Math.max(1, Math.ceil(leaderboard.total / leaderboard.page_size)) : 1;
+
+    const renderEntry = (entry) => (
+        <tr key={entry.name}>
+            <td>{entry.rank}</td>
+            <td>{entry.name}</td>
+            <td>{entry.score}</td>
+            <td>{entry.wins}/{entry.draws}/{entry.losses}</td>
+            <td>{Math.round(entry.rating)}</td>
+        </tr>
+    );
+
+    return (
+        <div>
+            <h1>Leaderboard</h1>
+            <select value={period} onChange={(event) => { setPeriod(event.target.value); setPage(1); }}>
+                <option value="all-time">All time</option>
+                <option value="monthly">Last month</option>
+                <option value="weekly">Last week</option>
+            </select>
+            <select value={variant} onChange={(event) => { setVariant(event.target.value); setPage(1); }}>
+                <option value="">All variants</option>
+                <option value="classic">Classic</option>
+                <option value="gomoku">Gomoku</option>
+                <option value="ultimate">Ultimate</option>
+            </select>
+            {error && <p>{error}</p>}
+            {leaderboard && (
+                <div>
+                    <table>
+                        <thead>
+                            <tr><th>Rank</th><th>Player</th><th>Score</th><th>W/D/L</th><th>Rating</th></tr>
+                        </thead>
+                        <tbody>
+                            {leaderboard.entries.map(renderEntry)}
+                        </tbody>
+                    </table>
+                    {leaderboard.me
+                        ? <p>Your rank: {leaderboard.me.rank} of {leaderboard.total} with a score of {leaderboard.me.score}</p>
+                        : <p>You have no finished rated game in this period</p>}
+                    <button disabled={page <= 1} onClick={() => setPage(page - 1)}>Previous</button>
+                    <span> Page {page} of {lastPage} </span>
+                    <button disabled={page >= lastPage} onClick={() => setPage(page + 1)}>Next</button>
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default LeaderboardPage;
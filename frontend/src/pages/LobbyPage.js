@@ -3,44 +3,142 @@ import { useSelector } from 'react-redux';
 import { useNavigate } from 'react-router-dom';
 import LogoutButton from '../components/LogoutButton';
 import LeaveQueueButton from '../components/LeaveQueueButton';
+import '../axiosConfig';
+import axios from 'axios';
 
 const LobbyPage = () => {
   const [status, setStatus] = useState('');
   const [gameId, setGameId] = useState(null);
   const [messages, setMessages] = useState([]); // Added to store incoming messages
   const [searchPlayerId, setSearchPlayerId] = useState(''); // Added to store the player ID to search
+  const [spectateGameId, setSpectateGameId] = useState('');
+  const [liveGames, setLiveGames] = useState([]);
+  const [inviteCode, setInviteCode] = useState('');
+  const [joinCode, setJoinCode] = useState('');
+  const [opponent, setOpponent] = useState('');
+  const [challenges, setChallenges] = useState([]);
+  const [rated, setRated] = useState(true);
+  const [rematch, setRematch] = useState(false);
+  const [variant, setVariant] = useState('classic');
+  // Board of the queue and computer games, the one of the variant when not custom
+  const [customBoard, setCustomBoard] = useState(false);
+  const [boardSize, setBoardSize] = useState({ width: 4, height: 4, win_length: 3 });
+  const [botLevel, setBotLevel] = useState('perfect');
   const navigate = useNavigate();
   const socket = useSelector((state) => state.websocket.connection);
 
   useEffect(() => {
     socket.onmessage = (event) => {
       const data = JSON.parse(event.data);
-      if (data.type === 'gameCreated') {
+      if (data.type === 'gameCreated' || data.type === 'tournamentGameReady') {
         setGameId(data.gameId)
         navigate(`/game/${data.gameId}`);
       } else if (data.type === 'waiting') {
-        setStatus('Waiting for an opponent...');
+        const queueStatus = JSON.parse(data.message);
+        const estimation = queueStatus.estimated_wait < 0
+          ? 'unknown'
+          : `about ${Math.ceil(queueStatus.estimated_wait)}s`;
+        setStatus(`Waiting for an opponent... position ${queueStatus.position}, estimated wait ${estimation}`);
+      } else if (data.type === 'queueTimeout') {
+        setStatus('No opponent found, please join the queue again.');
       } else if (data.type === 'playerProfile') {
         navigate(`/player/${data.id}`);
       } else if (data.type === 'message') {
         setMessages(prevMessages => [...prevMessages, data.message]);
+      } else if (data.type === 'privateGameCreated') {
+        setInviteCode(JSON.parse(data.message).code);
+      } else if (data.type === 'challenge') {
+        const invitation = JSON.parse(data.message);
+        setChallenges(prevChallenges => [...prevChallenges.filter(c => c.code !== invitation.code), invitation]);
+      } else if (data.type === 'challengeSent') {
+        setStatus(`Challenge sent to ${JSON.parse(data.message).to_name}`);
+      } else if (data.type === 'challengeDeclined') {
+        setStatus(`${JSON.parse(data.message).to_name} declined your challenge`);
+      } else if (data.type === 'invitationExpired') {
+        const invitation = JSON.parse(data.message);
+        setChallenges(prevChallenges => prevChallenges.filter(c => c.code !== invitation.code));
+        if (invitation.from_name === localStorage.getItem('username')) {
+          setStatus('Your invitation expired');
+        }
+      } else if (data.type === 'error') {
+        setStatus(data.message);
+      } else if (data.type === 'lobbyGameCreated') {
+        const game = JSON.parse(data.message);
+        setLiveGames(prevGames => [game, ...prevGames.filter(g => g.id !== game.id)]);
+      } else if (data.type === 'lobbyGameEnded') {
+        setLiveGames(prevGames => prevGames.filter(g => g.id !== data.gameId));
       }
     };
 
-    return () => {};
+    // Games not started yet are live too
+    Promise.all(['Started', 'In-Progress'].map(status => axios.get('/games', { params: { status: status } })))
+      .then(responses => {
+        const games = responses.flatMap(response => response.data.games);
+        games.sort((a, b) => b.id - a.id);
+        setLiveGames(games);
+      })
+      .catch(error => console.error('Failed to list games:', error));
+    socket.send(JSON.stringify({ type: "SubscribeLobby", gameId: -1 }));
+
+    return () => {
+      if (socket.readyState === WebSocket.OPEN) {
+        socket.send(JSON.stringify({ type: "UnsubscribeLobby", gameId: -1 }));
+      }
+    };
   }, []);
 
+  const requestedSize = () => (customBoard && variant !== 'ultimate' ? boardSize : undefined);
+
+  const updateBoardSize = (field, value) => {
+    setBoardSize({ ...boardSize, [field]: parseInt(value, 10) || 0 });
+  };
+
   const joinQueue = () => {
     if (socket) {
       socket.send(JSON.stringify({
         type: "JoinQueue",
         message: "JoinQueue",
         gameId: -1, 
-        username: localStorage.getItem('username')
+        username: localStorage.getItem('username'),
+        variant: variant,
+        rematch: rematch,
+        size: requestedSize()
+      }));
+    }
+  };
+
+  const playBot = () => {
+    if (socket) {
+      socket.send(JSON.stringify({
+        type: "StartBotGame",
+        message: botLevel,
+        gameId: -1,
+        username: localStorage.getItem('username'),
+        variant: variant,
+        size: requestedSize()
       }));
     }
   };
 
+  const sendInvitationMessage = (type, message) => {
+    if (socket) {
+      socket.send(JSON.stringify({
+        type: type,
+        message: message,
+        gameId: -1,
+        username: localStorage.getItem('username'),
+        variant: variant,
+        size: requestedSize(),
+        rated: rated
+      }));
+    }
+  };
+
+  const answerChallenge = (type, code) => {
+    sendInvitationMessage(type, code);
+    setChallenges(prevChallenges => prevChallenges.filter(c => c.code !== code));
+  };
+
   const ping = () => {
     if (socket) {
       socket.send(JSON.stringify({
@@ -79,18 +177,97 @@ const LobbyPage = () => {
           </ul>
         </div>
       )}
+      <select value={variant} onChange={(event) => setVariant(event.target.value)}>
+        <option value="classic">Classic</option>
+        <option value="gomoku">Gomoku</option>
+        <option value="ultimate">Ultimate</option>
+      </select>
+      {variant !== 'ultimate' && (
+        <label>
+          <input type="checkbox" checked={customBoard} onChange={(event) => setCustomBoard(event.target.checked)} />
+          Custom board
+        </label>
+      )}
+      {variant !== 'ultimate' && customBoard && (
+        <span>
+          <input type="number" min="1" max="19" value={boardSize.width} onChange={(event) => updateBoardSize('width', event.target.value)} title="Width" />
+          x
+          <input type="number" min="1" max="19" value={boardSize.height} onChange={(event) => updateBoardSize('height', event.target.value)} title="Height" />
+          , <input type="number" min="1" max="19" value={boardSize.win_length} onChange={(event) => updateBoardSize('win_length', event.target.value)} title="Marks in a row to win" />
+          in a row
+        </span>
+      )}
       <button onClick={joinQueue}>
         Join queue
       </button>
+      <label>
+        <input type="checkbox" checked={rematch} onChange={(event) => setRematch(event.target.checked)} />
+        Accept a rematch
+      </label>
+      <select value={botLevel} onChange={(event) => setBotLevel(event.target.value)}>
+        <option value="random">Random</option>
+        <option value="heuristic">Heuristic</option>
+        <option value="depth-limited">Depth-limited</option>
+        <option value="perfect">Perfect</option>
+      </select>
+      <button onClick={playBot}>
+        Play against the computer
+      </button>
       <button onClick={ping}>
         Ping
       </button>
+      <button onClick={() => navigate('/leaderboard')}>
+        Leaderboard
+      </button>
+      <button onClick={() => navigate('/tournaments')}>
+        Tournaments
+      </button>
       <LeaveQueueButton />
       <LogoutButton />
       <div>
         <input type="text" value={searchPlayerId} onChange={handleSearchPlayerIdChange} placeholder="Search player by ID" />
         <button onClick={searchPlayer}>Search</button>
       </div>
+      <div>
+        <label>
+          <input type="checkbox" checked={rated} onChange={(event) => setRated(event.target.checked)} />
+          Rated
+        </label>
+        <button onClick={() => sendInvitationMessage('CreatePrivateGame', '')}>Create private game</button>
+        {inviteCode && <span> Share this code with your opponent: <b>{inviteCode}</b></span>}
+      </div>
+      <div>
+        <input type="text" value={joinCode} onChange={(event) => setJoinCode(event.target.value)} placeholder="Invite code" />
+        <button onClick={() => sendInvitationMessage('JoinByCode', joinCode)}>Join by code</button>
+      </div>
+      <div>
+        <input type="text" value={opponent} onChange={(event) => setOpponent(event.target.value)} placeholder="Player name" />
+        <button onClick={() => sendInvitationMessage('Challenge', opponent)}>Challenge</button>
+      </div>
+      {challenges.length > 0 && (
+        <ul>
+          {challenges.map(challenge => (
+            <li key={challenge.code}>
+              {challenge.from_name} challenges you ({challenge.variant} {challenge.width}x{challenge.height}, {challenge.rated ? 'rated' : 'casual'})
+              {' '}<button onClick={() => answerChallenge('AcceptChallenge', challenge.code)}>Accept</button>
+              <button onClick={() => answerChallenge('DeclineChallenge', challenge.code)}>Decline</button>
+            </li>
+          ))}
+        </ul>
+      )}
+      <div>
+        <input type="text" value={spectateGameId} onChange={(event) => setSpectateGameId(event.target.value)} placeholder="Game ID" />
+        <button onClick={() => navigate(`/spectate/${spectateGameId}`)}>Spectate</button>
+      </div>
+      <h2>Live games</h2>
+      <ul>
+        {liveGames.map(game => (
+          <li key={game.id}>
+            {game.player_x_name} vs {game.player_o_name} ({game.variant}, {game.move_count} moves)
+            {' '}<button onClick={() => navigate(`/spectate/${game.id}`)}>Watch</button>
+          </li>
+        ))}
+      </ul>
     </div>
   );
 };
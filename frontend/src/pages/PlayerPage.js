@@ -1,5 +1,5 @@
 import React, { useEffect, useState } from 'react';
-import { useParams } from 'react-router-dom';
+import { Link, useParams } from 'react-router-dom';
 import { useSelector } from 'react-redux';
 
 const PlayerPage = () => {
@@ -48,11 +48,18 @@ const PlayerPage = () => {
                     <p>Wins: {player.wins}</p>
                     <p>Losses: {player.loses}</p>
                     <p>Draws: {player.draw}</p>
+                    <p>Win rate: {Math.round(player.win_rate * 100)}%</p>
+                    <p>Rating: {Math.round(player.rating.rating)} ± {Math.round(2 * player.rating.deviation)}</p>
+                    {player.rating_history && player.rating_history.length > 0 && (
+                        <p>Rating history: {player.rating_history.map(change => Math.round(change.rating)).join(' → ')}</p>
+                    )}
+                    {player.streak.count > 0 && <p>Current streak: {player.streak.count} {player.streak.result}</p>}
                     <h2>Games:</h2>
                     <ul>
                         {games.map(game => (
                             <li key={game.id}>
                                 Game ID: {game.id}, Status: {getStatusName(game.status)}
+                                {game.status === 2 && <> <Link to={`/replay/${game.id}`}>Replay</Link></>}
                             </li>
                         ))}
                     </ul>
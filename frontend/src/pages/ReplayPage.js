@@ -0,0 +1,81 @@
+import React, { useEffect, useState } from 'react';
+import { useParams } from 'react-router-dom';
+import { useSelector } from 'react-redux';
+import Board from '../components/Board';
+
+const ReplayPage = () => {
+    const { id } = useParams();
+    const [step, setStep] = useState(null);
+    const [speed, setSpeed] = useState('1');
+    const [finished, setFinished] = useState(false);
+    const [error, setError] = useState('');
+    const socket = useSelector((state) => state.websocket.connection);
+
+    useEffect(() => {
+        if (socket) {
+            socket.onmessage = (event) => {
+                const data = JSON.parse(event.data);
+                if (data.type === 'replay') {
+                    setStep(JSON.parse(data.message));
+                } else if (data.type === 'replayEnd') {
+                    setFinished(true);
+                } else if (data.type === 'error') {
+                    setError(data.message);
+                }
+            };
+        }
+        return () => {
+            if (socket && socket.readyState === WebSocket.OPEN) {
+                socket.send(JSON.stringify({ type: "StopReplay", gameId: parseInt(id) }));
+            }
+        };
+    }, [socket, id]);
+
+    const startReplay = () => {
+        if (socket) {
+            setError('');
+            setFinished(false);
+            socket.send(JSON.stringify({
+                type: "replay",
+                message: speed,
+                gameId: parseInt(id)
+            }));
+        }
+    };
+
+    const stopReplay = () => {
+        if (socket) {
+            socket.send(JSON.stringify({ type: "StopReplay", gameId: parseInt(id) }));
+        }
+    };
+
+    return (
+        <div>
+            <h1>Replay of game {id}</h1>
+            <select value={speed} onChange={(event) => setSpeed(event.target.value)}>
+                <option value="0.5">x0.5</option>
+                <option value="1">x1</option>
+                <option value="2">x2</option>
+                <option value="4">x4</option>
+            </select>
+            <button onClick={startReplay}>Play</button>
+            <button onClick={stopReplay}>Stop</button>
+            {error && <p>{error}</p>}
+            {step && (
+                <div>
+                    <p>Move {step.ply}{step.move && `: ${step.move.player} at ${step.move.x},${step.move.y}`}</p>
+                    <Board
+                        board={step.game.board}
+                        winningLine={step.game.winning_line || []}
+                        subBoards={step.game.variant === 'ultimate'}
+                        activeBoard={step.game.active_board || null}
+                        onClick={() => {}}
+                    />
+                </div>
+            )}
+            {finished && <p>End of the game</p>}
+        </div>
+    );
+};
+
+export default ReplayPage;
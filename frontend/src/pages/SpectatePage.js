@@ -0,0 +1,15 @@
+import React from 'react';
+import Game from '../components/Game';
+import LogoutButton from '../components/LogoutButton';
+
+function SpectatePage() {
+  return (
+    <div>
+      <h1>Spectating</h1>
+        <Game spectate />
+        <LogoutButton />
+    </div>
+  );
+}
+
+export default SpectatePage;
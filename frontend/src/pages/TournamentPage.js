@@ -0,0 +1,104 @@
+import React, { useEffect, useState } from 'react';
+import { useNavigate, useParams } from 'react-router-dom';
+import { useSelector } from 'react-redux';
+import '../axiosConfig';
+import axios from 'axios';
+
+const statusNames = ['Registration', 'In progress', 'Finished'];
+const resultNames = ['Ongoing', 'X wins', 'O wins', 'Draw'];
+
+const TournamentPage = () => {
+    const { id } = useParams();
+    const [tournament, setTournament] = useState(null);
+    const [error, setError] = useState('');
+    const navigate = useNavigate();
+    const socket = useSelector((state) => state.websocket.connection);
+    const username = localStorage.getItem('username');
+
+    const loadTournament = () => {
+        axios.get(`/tournament/${id}`)
+            .then(response => setTournament(response.data))
+            .catch(error => setError('Failed to load the tournament'));
+    };
+
+    useEffect(loadTournament, [id]);
+
+    useEffect(() => {
+        if (socket) {
+            socket.onmessage = (event) => {
+                const data = JSON.parse(event.data);
+                if (data.type === 'tournamentGameReady') {
+                    navigate(`/game/${data.gameId}`);
+                } else if (data.type === 'tournamentFinished') {
+                    loadTournament();
+                }
+            };
+        }
+    }, [socket, id]);
+
+    const act = (action) => {
+        axios.post(`/tournament/${id}/${action}`)
+            .then(() => {
+                setError('');
+                loadTournament();
+            })
+            .catch(error => setError(error.response ? error.response.data : `Failed to ${action} the tournament`));
+    };
+
+    if (!tournament) {
+        return <div>{error || 'Loading...'}</div>;
+    }
+
+    const registered = tournament.players.includes(username);
+    const swiss = tournament.format === 'swiss';
+    return (
+        <div>
+            <h1>{tournament.name}</h1>
+            <p>{tournament.format}, {tournament.variant}{tournament.rated ? ', rated' : ''} - {statusNames[tournament.status]}{tournament.round > 0 && `, round ${tournament.round}`}</p>
+            {error && <p>{error}</p>}
+            {tournament.status === 0 && (
+                <div>
+                    {registered
+                        ? <button onClick={() => act('leave')}>Leave</button>
+                        : <button onClick={() => act('join')}>Join</button>}
+                    {tournament.creator === username && <button onClick={() => act('start')}>Start</button>}
+                </div>
+            )}
+            <h2>Standings</h2>
+            <table>
+                <thead>
+                    <tr><th>Rank</th><th>Player</th><th>Score</th><th>W/D/L</th>{swiss && <th>Buchholz</th>}{swiss && <th>Sonneborn-Berger</th>}</tr>
+                </thead>
+                <tbody>
+                    {tournament.standings.map(standing => (
+                        <tr key={standing.name}>
+                            <td>{standing.rank}</td>
+                            <td>{standing.name}{standing.eliminated && ' (eliminated)'}</td>
+                            <td>{standing.score}</td>
+                            <td>{standing.wins}/{standing.draws}/{standing.losses}</td>
+                            {swiss && <td>{standing.buchholz}</td>}
+                            {swiss && <td>{standing.sonneborn_berger}</td>}
+                        </tr>
+                    ))}
+                </tbody>
+            </table>
+            <h2>Games</h2>
+            <ul>
+                {tournament.games.map(game => (
+                    <li key={`${game.round}-${game.board}-${game.replay}`}>
+                        Round {game.round}, board {game.board}{game.replay > 0 && ` (replay ${game.replay})`}:{' '}
+                        {game.player_o
+                            ? <span>
+                                {game.player_x} vs {game.player_o} - {game.status === 2 ? resultNames[game.result] : 'playing'}
+                                {game.status !== 2 && [game.player_x, game.player_o].includes(username) &&
+                                    <button onClick={() => navigate(`/game/${game.game_id}`)}>Play</button>}
+                              </span>
+                            : <span>{game.player_x} has a bye</span>}
+                    </li>
+                ))}
+            </ul>
+        </div>
+    );
+};
+
+export default TournamentPage;
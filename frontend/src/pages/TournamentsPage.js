@@ -0,0 +1,87 @@
+import React, { useEffect, useState } from 'react';
+import { useNavigate } from 'react-router-dom';
+import '../axiosConfig';
+import axios from 'axios';
+
+const statusNames = ['Registration', 'In progress', 'Finished'];
+
+const TournamentsPage = () => {
+    const [tournaments, setTournaments] = useState([]);
+    const [name, setName] = useState('');
+    const [format, setFormat] = useState('single-elimination');
+    const [variant, setVariant] = useState('classic');
+    const [rated, setRated] = useState(false);
+    const [customBoard, setCustomBoard] = useState(false);
+    const [boardSize, setBoardSize] = useState({ width: 4, height: 4, win_length: 3 });
+    const [error, setError] = useState('');
+    const navigate = useNavigate();
+
+    const loadTournaments = () => {
+        axios.get('/tournaments')
+            .then(response => setTournaments(response.data))
+            .catch(error => setError('Failed to load the tournaments'));
+    };
+
+    useEffect(loadTournaments, []);
+
+    const createTournament = () => {
+        const size = customBoard && variant !== 'ultimate' ? boardSize : undefined;
+        axios.post('/tournaments', { name: name, format: format, variant: variant, size: size, rated: rated })
+            .then(response => navigate(`/tournament/${response.data.id}`))
+            .catch(error => setError(error.response ? error.response.data : 'Failed to create the tournament'));
+    };
+
+    const updateBoardSize = (field, value) => {
+        setBoardSize({ ...boardSize, [field]: parseInt(value, 10) || 0 });
+    };
+
+    return (
+        <div>
+            <h1>Tournaments</h1>
+            {error && <p>{error}</p>}
+            <div>
+                <input type="text" value={name} onChange={(event) => setName(event.target.value)} placeholder="Tournament name" />
+                <select value={format} onChange={(event) => setFormat(event.target.value)}>
+                    <option value="single-elimination">Single elimination</option>
+                    <option value="round-robin">Round robin</option>
+                    <option value="swiss">Swiss</option>
+                </select>
+                <select value={variant} onChange={(event) => setVariant(event.target.value)}>
+                    <option value="classic">Classic</option>
+                    <option value="gomoku">Gomoku</option>
+                    <option value="ultimate">Ultimate</option>
+                </select>
+                {variant !== 'ultimate' && (
+                    <label>
+                        <input type="checkbox" checked={customBoard} onChange={(event) => setCustomBoard(event.target.checked)} />
+                        Custom board
+                    </label>
+                )}
+                {variant !== 'ultimate' && customBoard && (
+                    <span>
+                        <input type="number" min="1" max="19" value={boardSize.width} onChange={(event) => updateBoardSize('width', event.target.value)} title="Width" />
+                        x
+                        <input type="number" min="1" max="19" value={boardSize.height} onChange={(event) => updateBoardSize('height', event.target.value)} title="Height" />
+                        , <input type="number" min="1" max="19" value={boardSize.win_length} onChange={(event) => updateBoardSize('win_length', event.target.value)} title="Marks in a row to win" />
+                        in a row
+                    </span>
+                )}
+                <label>
+                    <input type="checkbox" checked={rated} onChange={(event) => setRated(event.target.checked)} />
+                    Rated
+                </label>
+                <button onClick={createTournament}>Create tournament</button>
+            </div>
+            <ul>
+                {tournaments.map(tournament => (
+                    <li key={tournament.id}>
+                        {tournament.name} ({tournament.format}, {tournament.variant} {tournament.width}x{tournament.height}) - {statusNames[tournament.status]}, {tournament.players.length} players
+                        {' '}<button onClick={() => navigate(`/tournament/${tournament.id}`)}>Open</button>
+                    </li>
+                ))}
+            </ul>
+        </div>
+    );
+};
+
+export default TournamentsPage;